@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/accrualsim"
+	"github.com/etoneja/go-gophermart/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+func loadRules(path string) ([]accrualsim.RewardRule, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []accrualsim.RewardRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+func main() {
+	var (
+		address        string
+		rulesPath      string
+		defaultAccrual string
+		cfg            accrualsim.Config
+	)
+
+	flag.StringVar(&address, "a", ":8081", "Server address to listen on")
+	flag.StringVar(&rulesPath, "rules", "", "Path to a JSON array of reward rules")
+	flag.BoolVar(&cfg.AutoRegister, "auto-register", true, "Register unknown orders on their first request")
+	flag.StringVar(&defaultAccrual, "default-accrual", "100", "Accrual of auto-registered orders, in rubles")
+	flag.DurationVar(&cfg.RegisterDelay, "register-delay", 2*time.Second, "Time an auto-registered order answers 204")
+	flag.DurationVar(&cfg.StepDuration, "step", 5*time.Second, "Time an order stays REGISTERED and then PROCESSING")
+	flag.DurationVar(&cfg.MinDelay, "min-delay", 0, "Min response delay")
+	flag.DurationVar(&cfg.MaxDelay, "max-delay", 200*time.Millisecond, "Max response delay")
+	flag.IntVar(&cfg.RateLimit, "rate-limit", 0, "Requests per minute before answering 429, 0 disables throttling")
+	flag.IntVar(&cfg.BatchSize, "batch-size", 0, "Max orders per batch lookup, 0 disables the batch endpoint")
+	flag.Parse()
+
+	logger := zerolog.New(os.Stdout).With().Timestamp().Str("component", "accrual_sim").Logger()
+
+	accrual, err := models.ParseMoney(defaultAccrual)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid default accrual")
+	}
+	cfg.DefaultAccrual = accrual
+
+	cfg.Rules, err = loadRules(rulesPath)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to load reward rules")
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+
+	server := &http.Server{
+		Addr:    address,
+		Handler: accrualsim.NewSimulator(cfg).Handler(),
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error().Err(err).Msg("Error during server shutdown")
+		}
+	}()
+
+	logger.Info().Str("address", address).Int("rules", len(cfg.Rules)).Msg("Accrual simulator started")
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Fatal().Err(err).Msg("Server error")
+	}
+
+	logger.Info().Msg("Accrual simulator stopped")
+}
@@ -5,58 +5,29 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/etoneja/go-gophermart/internal/api"
-	"github.com/etoneja/go-gophermart/internal/processor"
 	"github.com/rs/zerolog"
 )
 
+// Usage: gophermart [serve|worker|all] [flags]
+//
+// serve runs only the HTTP API, worker only the background processors and
+// all (the default) both in one process.
 func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
 
-	baseLogger := zerolog.New(os.Stdout).With().Timestamp().Logger()
-
-	apiLogger := baseLogger.With().Str("component", "api").Logger()
-	processorLogger := baseLogger.With().Str("component", "processor").Logger()
-
-	application, err := app.NewAPIApp(ctx, apiLogger)
+	application, err := app.New(ctx, os.Args[1:], logger)
 	if err != nil {
-		baseLogger.Fatal().Err(err).Msg("Failed to initialize application")
-	}
-
-	processor := processor.NewOrderProcessor(application.Config, application.DB, processorLogger)
-	go processor.Run(ctx)
-
-	serverErrChan := make(chan error, 1)
-	go func() {
-		serverErrChan <- application.Run()
-	}()
-
-	select {
-	case <-ctx.Done():
-		baseLogger.Info().Msgf("Received shutdown signal, initiating graceful shutdown...")
-	case err := <-serverErrChan:
-		if err != nil {
-			baseLogger.Error().Err(err).Msg("Server error, initiating shutdown...")
-			cancel()
-		}
+		logger.Fatal().Err(err).Msg("Failed to initialize application")
 	}
 
-	baseLogger.Info().Msg("Shutting down server...")
-
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer shutdownCancel()
-
-	if err := application.Shutdown(shutdownCtx); err != nil {
-		baseLogger.Error().Err(err).Msg("Error during server shutdown")
+	if err := application.Run(ctx); err != nil {
+		logger.Error().Err(err).Msg("Application stopped with error")
+		cancel()
+		os.Exit(1)
 	}
-
-	processor.Stop()
-
-	baseLogger.Info().Msg("Server stopped gracefully")
 }
@@ -0,0 +1,240 @@
+package accrualclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/models"
+)
+
+const (
+	capabilitiesPath        = "/api/capabilities"
+	capabilitiesTTL         = 10 * time.Minute
+	capabilitiesRetryTTL    = 30 * time.Second
+	defaultBatchConcurrency = 4
+)
+
+// OrderResult is the outcome of looking up one order of a batch, Err is set
+// exactly when GetOrder would have returned an error for the order.
+type OrderResult struct {
+	ID    string
+	Order *models.AccrualOrderModel
+	Err   error
+}
+
+// capabilities is advertised by accrual systems that support batch lookups.
+type capabilities struct {
+	BatchEndpoint string `json:"batch_endpoint"`
+	MaxBatchSize  int    `json:"max_batch_size"`
+}
+
+type capabilitiesCache struct {
+	mu        sync.Mutex
+	caps      *capabilities
+	expiresAt time.Time
+	probing   bool
+}
+
+type batchRequest struct {
+	Orders []string `json:"orders"`
+}
+
+// GetOrders looks the orders up through the batch endpoint when the accrual
+// system advertises one and otherwise calls GetOrder for each order with
+// bounded concurrency. Results are returned in the order of orderIDs.
+func (c *AccrualClient) GetOrders(ctx context.Context, orderIDs []string) []OrderResult {
+	if len(orderIDs) == 0 {
+		return nil
+	}
+
+	if c.dialect.supportsBatch() {
+		if caps := c.getCapabilities(ctx); caps != nil && caps.BatchEndpoint != "" {
+			return c.getOrdersBatch(ctx, caps, orderIDs)
+		}
+	}
+	return c.getOrdersFanOut(ctx, orderIDs)
+}
+
+// BatchSize is the advertised max batch size when the accrual system has a
+// batch endpoint and the fan-out concurrency otherwise.
+func (c *AccrualClient) BatchSize(ctx context.Context) int {
+	if c.dialect.supportsBatch() {
+		if caps := c.getCapabilities(ctx); caps != nil && caps.BatchEndpoint != "" {
+			return max(caps.MaxBatchSize, 0)
+		}
+	}
+	return max(c.batchConcurrency, 1)
+}
+
+func (c *AccrualClient) getOrdersFanOut(ctx context.Context, orderIDs []string) []OrderResult {
+	results := make([]OrderResult, len(orderIDs))
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, max(c.batchConcurrency, 1))
+	)
+	for i, orderID := range orderIDs {
+		results[i].ID = orderID
+
+		select {
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(result *OrderResult) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			result.Order, result.Err = c.GetOrder(ctx, result.ID)
+		}(&results[i])
+	}
+	wg.Wait()
+
+	return results
+}
+
+func (c *AccrualClient) getOrdersBatch(ctx context.Context, caps *capabilities, orderIDs []string) []OrderResult {
+	results := make([]OrderResult, 0, len(orderIDs))
+
+	size := caps.MaxBatchSize
+	if size <= 0 {
+		size = len(orderIDs)
+	}
+
+	for start := 0; start < len(orderIDs); start += size {
+		chunk := orderIDs[start:min(start+size, len(orderIDs))]
+
+		var orders map[string]*models.AccrualOrderModel
+		err := c.call(ctx, func() error {
+			var err error
+			orders, err = c.postBatch(ctx, caps.BatchEndpoint, chunk)
+			return err
+		})
+
+		for _, orderID := range chunk {
+			result := OrderResult{ID: orderID, Err: err}
+			if err == nil {
+				result.Order = orders[orderID]
+				if result.Order == nil {
+					result.Err = ErrOrderNotRegistered
+				}
+			}
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+// postBatch returns the orders known to the accrual system, orders missing
+// from the response are not registered yet.
+func (c *AccrualClient) postBatch(ctx context.Context, endpoint string, orderIDs []string) (map[string]*models.AccrualOrderModel, error) {
+	if err := c.rateLimiter.wait(ctx); err != nil {
+		return nil, notSent(err)
+	}
+
+	payload, err := json.Marshal(batchRequest{Orders: orderIDs})
+	if err != nil {
+		return nil, notSent(fmt.Errorf("failed to marshal request: %w", err))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, notSent(fmt.Errorf("failed to create request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: request failed: %w", ErrAccrualUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.statusError(ctx, resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read response body: %w", ErrAccrualUnavailable, err)
+	}
+
+	var responses []models.AccrualOrderResponse
+	if err := json.Unmarshal(body, &responses); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal response: %w", ErrUnexpectedResponse, err)
+	}
+
+	orders := make(map[string]*models.AccrualOrderModel, len(responses))
+	for i := range responses {
+		orders[responses[i].Order] = responses[i].ToModel()
+	}
+	return orders, nil
+}
+
+// getCapabilities returns the cached capabilities, probing the accrual system
+// once per capabilitiesTTL. A failed probe keeps the previous result for
+// capabilitiesRetryTTL. Callers arriving during a probe don't wait for it and
+// get the previous result. A nil result means batch lookups are unsupported
+// or unknown for now.
+func (c *AccrualClient) getCapabilities(ctx context.Context) *capabilities {
+	c.caps.mu.Lock()
+	if c.caps.probing || time.Now().Before(c.caps.expiresAt) {
+		caps := c.caps.caps
+		c.caps.mu.Unlock()
+		return caps
+	}
+	c.caps.probing = true
+	c.caps.mu.Unlock()
+
+	caps, err := c.fetchCapabilities(ctx)
+
+	c.caps.mu.Lock()
+	defer c.caps.mu.Unlock()
+	c.caps.probing = false
+	if err != nil {
+		c.caps.expiresAt = time.Now().Add(capabilitiesRetryTTL)
+		return c.caps.caps
+	}
+	c.caps.caps = caps
+	c.caps.expiresAt = time.Now().Add(capabilitiesTTL)
+	return caps
+}
+
+func (c *AccrualClient) fetchCapabilities(ctx context.Context) (*capabilities, error) {
+	if err := c.rateLimiter.wait(ctx); err != nil {
+		return nil, notSent(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+capabilitiesPath, nil)
+	if err != nil {
+		return nil, notSent(fmt.Errorf("failed to create request: %w", err))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: request failed: %w", ErrAccrualUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		return nil, nil
+	default:
+		return nil, c.statusError(ctx, resp)
+	}
+
+	var caps capabilities
+	if err := json.NewDecoder(resp.Body).Decode(&caps); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal response: %w", ErrUnexpectedResponse, err)
+	}
+	return &caps, nil
+}
@@ -0,0 +1,103 @@
+package accrualclient
+
+import (
+	"sync"
+	"time"
+)
+
+type BreakerState string
+
+const (
+	BreakerStateClosed   BreakerState = "closed"
+	BreakerStateOpen     BreakerState = "open"
+	BreakerStateHalfOpen BreakerState = "half-open"
+)
+
+type breakerOutcome int
+
+const (
+	breakerSuccess breakerOutcome = iota
+	breakerFailure
+	// breakerIgnored releases a half-open probe without changing the state,
+	// e.g. when the caller's context was canceled.
+	breakerIgnored
+)
+
+// circuitBreaker opens after threshold consecutive failures, rejects calls
+// for cooldown and then lets a single probe through in the half-open state.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     BreakerStateClosed,
+	}
+}
+
+func (b *circuitBreaker) currentState(now time.Time) BreakerState {
+	if b.state == BreakerStateOpen && now.Sub(b.openedAt) >= b.cooldown {
+		b.state = BreakerStateHalfOpen
+		b.probing = false
+	}
+	return b.state
+}
+
+func (b *circuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.currentState(time.Now())
+}
+
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.threshold <= 0 {
+		return true
+	}
+
+	switch b.currentState(time.Now()) {
+	case BreakerStateOpen:
+		return false
+	case BreakerStateHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+	}
+	return true
+}
+
+func (b *circuitBreaker) record(outcome breakerOutcome) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.threshold <= 0 {
+		return
+	}
+
+	switch outcome {
+	case breakerSuccess:
+		b.state = BreakerStateClosed
+		b.failures = 0
+		b.probing = false
+	case breakerFailure:
+		b.failures++
+		if b.state == BreakerStateHalfOpen || b.failures >= b.threshold {
+			b.state = BreakerStateOpen
+			b.openedAt = time.Now()
+		}
+		b.probing = false
+	case breakerIgnored:
+		b.probing = false
+	}
+}
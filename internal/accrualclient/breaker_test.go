@@ -0,0 +1,57 @@
+package accrualclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_Transitions(t *testing.T) {
+	b := newCircuitBreaker(3, 20*time.Millisecond)
+
+	for range 2 {
+		assert.True(t, b.allow())
+		b.record(breakerFailure)
+	}
+	assert.Equal(t, BreakerStateClosed, b.State())
+
+	assert.True(t, b.allow())
+	b.record(breakerSuccess)
+	assert.True(t, b.allow())
+	b.record(breakerFailure)
+	assert.Equal(t, BreakerStateClosed, b.State(), "success resets consecutive failures")
+
+	for range 2 {
+		assert.True(t, b.allow())
+		b.record(breakerFailure)
+	}
+	assert.Equal(t, BreakerStateOpen, b.State())
+	assert.False(t, b.allow())
+
+	time.Sleep(25 * time.Millisecond)
+	assert.Equal(t, BreakerStateHalfOpen, b.State())
+	assert.True(t, b.allow())
+	assert.False(t, b.allow(), "only one probe in half-open")
+
+	b.record(breakerFailure)
+	assert.Equal(t, BreakerStateOpen, b.State(), "failed probe reopens")
+
+	time.Sleep(25 * time.Millisecond)
+	assert.True(t, b.allow())
+	b.record(breakerIgnored)
+	assert.Equal(t, BreakerStateHalfOpen, b.State())
+	assert.True(t, b.allow(), "ignored probe releases the slot")
+	b.record(breakerSuccess)
+	assert.Equal(t, BreakerStateClosed, b.State())
+}
+
+func TestCircuitBreaker_Disabled(t *testing.T) {
+	b := newCircuitBreaker(0, time.Minute)
+
+	for range 10 {
+		assert.True(t, b.allow())
+		b.record(breakerFailure)
+	}
+	assert.Equal(t, BreakerStateClosed, b.State())
+}
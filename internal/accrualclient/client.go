@@ -2,7 +2,8 @@ package accrualclient
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,70 +11,216 @@ import (
 	"time"
 
 	"github.com/etoneja/go-gophermart/internal/errs"
+	"github.com/etoneja/go-gophermart/internal/metrics"
 	"github.com/etoneja/go-gophermart/internal/models"
+	"github.com/etoneja/go-gophermart/internal/utils"
 )
 
 var defaultRetryAfterOnRateLimit = 30 * time.Second
 
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+	defaultMaxRetries       = 2
+	defaultRetryBaseDelay   = 200 * time.Millisecond
+	maxRetryDelay           = 5 * time.Second
+)
+
 type AccrualClient struct {
-	baseURL     string
-	client      *http.Client
-	rateLimiter *rateLimiter
+	name           string
+	baseURL        string
+	client         *http.Client
+	dialect        dialect
+	rateLimiter    Limiter
+	breaker        *circuitBreaker
+	maxRetries     int
+	retryBaseDelay time.Duration
+
+	batchConcurrency int
+	caps             capabilitiesCache
+
+	tlsConfig     *tls.Config
+	signingSecret string
 }
 
-func NewAccrualClient(baseURL string, timeout time.Duration) *AccrualClient {
-	return &AccrualClient{
+func NewAccrualClient(baseURL string, timeout time.Duration, opts ...Option) *AccrualClient {
+	c := &AccrualClient{
+		name:    DefaultProvider,
 		baseURL: baseURL,
 		client: &http.Client{
 			Timeout: timeout,
 		},
-		rateLimiter: NewRateLimiter(),
+		dialect:        gophermartDialect{},
+		rateLimiter:    NewRateLimiter(0),
+		breaker:        newCircuitBreaker(defaultBreakerThreshold, defaultBreakerCooldown),
+		maxRetries:     defaultMaxRetries,
+		retryBaseDelay: defaultRetryBaseDelay,
+
+		batchConcurrency: defaultBatchConcurrency,
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
+	c.buildTransport()
+
+	return c
+}
+
+// NewPartnerClient creates a client for the partner calculation service, it
+// accepts the same options as NewAccrualClient.
+func NewPartnerClient(baseURL, token string, timeout time.Duration, opts ...Option) *AccrualClient {
+	opts = append([]Option{WithProviderName(PartnerProvider)}, opts...)
+	c := NewAccrualClient(baseURL, timeout, opts...)
+	c.dialect = partnerDialect{token: token}
+	return c
 }
 
 func (c *AccrualClient) IsRateLimited() bool {
 	return c.rateLimiter.isBlocked()
 }
 
+func (c *AccrualClient) BreakerState() BreakerState {
+	return c.breaker.State()
+}
+
+// Ping checks the accrual system is reachable, any response below 500 will
+// do. It skips the rate limiter and retries so probes don't eat into the
+// request quota, and fails fast with ErrCircuitOpen while the breaker is open.
+func (c *AccrualClient) Ping(ctx context.Context) error {
+	if c.breaker.State() == BreakerStateOpen {
+		return ErrCircuitOpen
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.baseURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: request failed: %w", ErrAccrualUnavailable, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: status %d", ErrAccrualUnavailable, resp.StatusCode)
+	}
+	return nil
+}
+
+// GetOrder fetches the order, retrying network errors and 5xx responses with
+// backoff. Outcomes feed the circuit breaker, which short-circuits calls with
+// ErrCircuitOpen while the accrual system is considered down.
 func (c *AccrualClient) GetOrder(ctx context.Context, orderID string) (*models.AccrualOrderModel, error) {
-	if c.IsRateLimited() {
-		return nil, errs.ErrRateLimit
+	var order *models.AccrualOrderModel
+	err := c.call(ctx, func() error {
+		var err error
+		order, err = c.getOrder(ctx, orderID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// call runs a single request through the circuit breaker and the retry loop.
+func (c *AccrualClient) call(ctx context.Context, request func() error) error {
+	if !c.breaker.allow() {
+		return ErrCircuitOpen
 	}
 
-	url := fmt.Sprintf("%s/api/orders/%s", c.baseURL, orderID)
+	var err error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(utils.Backoff(attempt, c.retryBaseDelay, maxRetryDelay))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				c.breaker.record(breakerIgnored)
+				return err
+			case <-timer.C:
+			}
+		}
+
+		err = request()
+
+		switch {
+		case ctx.Err() != nil:
+			c.breaker.record(breakerIgnored)
+			return err
+		case errors.Is(err, ErrAccrualUnavailable):
+			continue
+		case errors.Is(err, errs.ErrRateLimit), errors.As(err, new(*localError)):
+			c.breaker.record(breakerIgnored)
+			return err
+		default:
+			c.breaker.record(breakerSuccess)
+			return err
+		}
+	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	c.breaker.record(breakerFailure)
+	return err
+}
+
+func (c *AccrualClient) getOrder(ctx context.Context, orderID string) (*models.AccrualOrderModel, error) {
+	if err := c.rateLimiter.wait(ctx); err != nil {
+		return nil, notSent(err)
+	}
+
+	req, err := c.dialect.newOrderRequest(ctx, c.baseURL, orderID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, notSent(fmt.Errorf("failed to create request: %w", err))
 	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("%w: request failed: %w", ErrAccrualUnavailable, err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusTooManyRequests {
-		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
-		c.rateLimiter.blockFor(retryAfter)
-		return nil, errs.ErrRateLimit
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case c.dialect.notRegisteredStatus():
+		return nil, ErrOrderNotRegistered
+	default:
+		return nil, c.statusError(ctx, resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("%w: failed to read response body: %w", ErrAccrualUnavailable, err)
 	}
 
-	var accrualOrderResponse models.AccrualOrderResponse
-	if err := json.Unmarshal(body, &accrualOrderResponse); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	order, err := c.dialect.decodeOrder(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal response: %w", ErrUnexpectedResponse, err)
 	}
 
-	return accrualOrderResponse.ToModel(), nil
+	return order, nil
+}
+
+// statusError maps a non-successful response to an error, throttling the
+// client on 429.
+func (c *AccrualClient) statusError(ctx context.Context, resp *http.Response) error {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		body, _ := io.ReadAll(resp.Body)
+		if perMinute, ok := parseRateLimitBody(body); ok {
+			c.rateLimiter.setLimit(ctx, perMinute)
+		}
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+		c.rateLimiter.blockFor(ctx, retryAfter)
+		metrics.AccrualRateLimited.WithLabelValues(c.name).Inc()
+		metrics.RateLimiterBlocked.WithLabelValues(c.name).Add(retryAfter.Seconds())
+		return errs.ErrRateLimit
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return fmt.Errorf("%w: status code %d", ErrAccrualUnavailable, resp.StatusCode)
+	default:
+		return fmt.Errorf("%w: status code %d", ErrUnexpectedResponse, resp.StatusCode)
+	}
 }
 
 func parseRetryAfter(header string) time.Duration {
@@ -4,12 +4,18 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/etoneja/go-gophermart/internal/accrualsim"
+	"github.com/etoneja/go-gophermart/internal/errs"
 	"github.com/etoneja/go-gophermart/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 func TestAccrualClient_GetOrder(t *testing.T) {
@@ -18,7 +24,7 @@ func TestAccrualClient_GetOrder(t *testing.T) {
 		responseStatus int
 		responseBody   string
 		wantResult     *models.AccrualOrderModel
-		wantError      string
+		wantError      error
 	}{
 		{
 			name:           "successful response",
@@ -34,20 +40,36 @@ func TestAccrualClient_GetOrder(t *testing.T) {
 			},
 		},
 		{
-			name:           "order not found",
+			name:           "order not registered",
 			responseStatus: http.StatusNoContent,
-			wantError:      "unexpected status code: 204",
+			wantError:      ErrOrderNotRegistered,
 		},
 		{
 			name:           "invalid json",
 			responseStatus: http.StatusOK,
 			responseBody:   `{"order":123}`,
-			wantError:      "failed to unmarshal response",
+			wantError:      ErrUnexpectedResponse,
+		},
+		{
+			name:           "accrual with too many decimal places",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"order":"123","status":"PROCESSED","accrual":0.015}`,
+			wantError:      ErrUnexpectedResponse,
 		},
 		{
 			name:           "server error",
 			responseStatus: http.StatusInternalServerError,
-			wantError:      "unexpected status code: 500",
+			wantError:      ErrAccrualUnavailable,
+		},
+		{
+			name:           "bad request",
+			responseStatus: http.StatusBadRequest,
+			wantError:      ErrUnexpectedResponse,
+		},
+		{
+			name:           "rate limited",
+			responseStatus: http.StatusTooManyRequests,
+			wantError:      errs.ErrRateLimit,
 		},
 	}
 
@@ -66,9 +88,9 @@ func TestAccrualClient_GetOrder(t *testing.T) {
 
 			result, err := client.GetOrder(context.Background(), "123")
 
-			if tt.wantError != "" {
+			if tt.wantError != nil {
 				require.Error(t, err)
-				assert.Contains(t, err.Error(), tt.wantError)
+				assert.ErrorIs(t, err, tt.wantError)
 				return
 			}
 
@@ -85,10 +107,11 @@ func TestAccrualClient_RequestErrors(t *testing.T) {
 		}))
 		defer server.Close()
 
-		client := NewAccrualClient(server.URL, 100*time.Millisecond)
+		client := NewAccrualClient(server.URL, 100*time.Millisecond, WithRetries(0, 0))
 
 		_, err := client.GetOrder(context.Background(), "123")
 		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrAccrualUnavailable)
 		assert.Contains(t, err.Error(), "request failed")
 	})
 
@@ -107,3 +130,284 @@ func TestAccrualClient_RequestErrors(t *testing.T) {
 		assert.Contains(t, err.Error(), "context canceled")
 	})
 }
+
+func TestAccrualClient_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantError    error
+		wantRequests int32
+	}{
+		{
+			name:         "recovers after server errors",
+			statuses:     []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			wantRequests: 3,
+		},
+		{
+			name:         "gives up after max retries",
+			statuses:     []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError},
+			wantError:    ErrAccrualUnavailable,
+			wantRequests: 3,
+		},
+		{
+			name:         "does not retry not registered",
+			statuses:     []int{http.StatusNoContent},
+			wantError:    ErrOrderNotRegistered,
+			wantRequests: 1,
+		},
+		{
+			name:         "does not retry rate limit",
+			statuses:     []int{http.StatusTooManyRequests},
+			wantError:    errs.ErrRateLimit,
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := requests.Add(1)
+				status := tt.statuses[min(int(n), len(tt.statuses))-1]
+				w.WriteHeader(status)
+				if status == http.StatusOK {
+					_, _ = w.Write([]byte(`{"order":"123","status":"PROCESSED","accrual":1}`))
+				}
+			}))
+			defer server.Close()
+
+			client := NewAccrualClient(server.URL, time.Second, WithRetries(2, time.Millisecond))
+
+			_, err := client.GetOrder(context.Background(), "123")
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantRequests, requests.Load())
+		})
+	}
+}
+
+func TestAccrualClient_CircuitBreaker(t *testing.T) {
+	var requests atomic.Int32
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"order":"123","status":"PROCESSING"}`))
+	}))
+	defer server.Close()
+
+	client := NewAccrualClient(server.URL, time.Second,
+		WithRetries(0, 0),
+		WithCircuitBreaker(2, 50*time.Millisecond),
+	)
+	ctx := context.Background()
+
+	for range 2 {
+		_, err := client.GetOrder(ctx, "123")
+		assert.ErrorIs(t, err, ErrAccrualUnavailable)
+	}
+	assert.Equal(t, BreakerStateOpen, client.BreakerState())
+
+	_, err := client.GetOrder(ctx, "123")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(2), requests.Load())
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, BreakerStateHalfOpen, client.BreakerState())
+
+	healthy.Store(true)
+	_, err = client.GetOrder(ctx, "123")
+	require.NoError(t, err)
+	assert.Equal(t, BreakerStateClosed, client.BreakerState())
+}
+
+// failingLimiter fails wait while down is set, like a shared limiter that
+// can't reach its database.
+type failingLimiter struct {
+	down atomic.Bool
+}
+
+func (l *failingLimiter) wait(context.Context) error {
+	if l.down.Load() {
+		return ErrLimiterUnavailable
+	}
+	return nil
+}
+
+func (l *failingLimiter) blockFor(context.Context, time.Duration) {}
+
+func (l *failingLimiter) setLimit(context.Context, int) {}
+
+func (l *failingLimiter) isBlocked() bool { return false }
+
+func TestAccrualClient_CircuitBreakerIgnoresLocalErrors(t *testing.T) {
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"order":"123","status":"PROCESSING"}`))
+	}))
+	defer server.Close()
+
+	limiter := &failingLimiter{}
+	client := NewAccrualClient(server.URL, time.Second,
+		WithRetries(0, 0),
+		WithCircuitBreaker(2, 50*time.Millisecond),
+		WithRateLimiter(limiter),
+	)
+	ctx := context.Background()
+
+	for range 2 {
+		_, err := client.GetOrder(ctx, "123")
+		assert.ErrorIs(t, err, ErrAccrualUnavailable)
+	}
+	time.Sleep(60 * time.Millisecond)
+	healthy.Store(true)
+
+	limiter.down.Store(true)
+	_, err := client.GetOrder(ctx, "123")
+	assert.ErrorIs(t, err, ErrLimiterUnavailable)
+	assert.Equal(t, BreakerStateHalfOpen, client.BreakerState())
+
+	limiter.down.Store(false)
+	_, err = client.GetOrder(ctx, "123")
+	require.NoError(t, err)
+	assert.Equal(t, BreakerStateClosed, client.BreakerState())
+}
+
+func TestAccrualClient_Simulator(t *testing.T) {
+	_, server := accrualsim.NewServer(accrualsim.Config{
+		AutoRegister:   true,
+		DefaultAccrual: 12345,
+	})
+	defer server.Close()
+
+	client := NewAccrualClient(server.URL, time.Second)
+
+	order, err := client.GetOrder(context.Background(), "12345678903")
+	require.NoError(t, err)
+	assert.Equal(t, models.AccrualOrderStatusProcessed, order.Status)
+	require.NotNil(t, order.Accrual)
+	assert.Equal(t, int64(12345), *order.Accrual)
+}
+
+func TestAccrualClient_GetOrders(t *testing.T) {
+	tests := []struct {
+		name          string
+		batchSize     int
+		wantRequests  int32
+		wantBatchSize int
+	}{
+		{name: "fan out without batch endpoint", wantRequests: 3, wantBatchSize: defaultBatchConcurrency},
+		{name: "batch endpoint", batchSize: 2, wantRequests: 2, wantBatchSize: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := accrualsim.NewSimulator(accrualsim.Config{DefaultAccrual: 500, BatchSize: tt.batchSize})
+			require.NoError(t, sim.RegisterOrder("12345678903", nil))
+			require.NoError(t, sim.RegisterOrder("4561261212345467", nil))
+
+			var lookups atomic.Int32
+			handler := sim.Handler()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/capabilities" {
+					lookups.Add(1)
+				}
+				handler.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			client := NewAccrualClient(server.URL, time.Second)
+
+			ids := []string{"12345678903", "79927398713", "4561261212345467"}
+			results := client.GetOrders(context.Background(), ids)
+
+			require.Len(t, results, len(ids))
+			for i, result := range results {
+				assert.Equal(t, ids[i], result.ID)
+			}
+			assert.NoError(t, results[0].Err)
+			assert.Equal(t, models.AccrualOrderStatusProcessed, results[0].Order.Status)
+			assert.ErrorIs(t, results[1].Err, ErrOrderNotRegistered)
+			assert.NoError(t, results[2].Err)
+			assert.Equal(t, tt.wantRequests, lookups.Load())
+			assert.Equal(t, tt.wantBatchSize, client.BatchSize(context.Background()))
+		})
+	}
+}
+
+func TestAccrualClient_CachesFailedCapabilitiesProbe(t *testing.T) {
+	var probes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/capabilities" {
+			probes.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewAccrualClient(server.URL, time.Second, WithRetries(0, 0))
+
+	for range 3 {
+		results := client.GetOrders(context.Background(), []string{"12345678903"})
+		require.Len(t, results, 1)
+		assert.ErrorIs(t, results[0].Err, ErrOrderNotRegistered)
+	}
+	assert.Equal(t, int32(1), probes.Load())
+}
+
+func TestAccrualClient_PropagatesTraceContext(t *testing.T) {
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var traceparent atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent.Store(r.Header.Get("traceparent"))
+		_, _ = w.Write([]byte(`{"order":"123","status":"PROCESSING"}`))
+	}))
+	defer server.Close()
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "sync")
+	defer span.End()
+
+	client := NewAccrualClient(server.URL, time.Second)
+	_, err := client.GetOrder(ctx, "123")
+	require.NoError(t, err)
+
+	assert.Contains(t, traceparent.Load(), span.SpanContext().TraceID().String())
+}
+
+func TestAccrualClient_Ping(t *testing.T) {
+	status := atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodHead, r.Method)
+		w.WriteHeader(int(status.Load()))
+	}))
+
+	client := NewAccrualClient(server.URL, time.Second)
+
+	status.Store(http.StatusNotFound)
+	assert.NoError(t, client.Ping(context.Background()))
+
+	status.Store(http.StatusServiceUnavailable)
+	assert.ErrorIs(t, client.Ping(context.Background()), ErrAccrualUnavailable)
+
+	server.Close()
+	assert.ErrorIs(t, client.Ping(context.Background()), ErrAccrualUnavailable)
+}
@@ -0,0 +1,109 @@
+package accrualclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/etoneja/go-gophermart/internal/models"
+)
+
+// dialect adapts AccrualClient to the API of a particular accrual provider,
+// transport concerns like rate limiting and retries stay in the client.
+type dialect interface {
+	newOrderRequest(ctx context.Context, baseURL, orderID string) (*http.Request, error)
+	// notRegisteredStatus is the status code of orders the provider doesn't know yet.
+	notRegisteredStatus() int
+	decodeOrder(body []byte) (*models.AccrualOrderModel, error)
+	// supportsBatch reports whether the provider may advertise a batch endpoint.
+	supportsBatch() bool
+}
+
+// gophermartDialect speaks the accrual API from the project spec.
+type gophermartDialect struct{}
+
+func (gophermartDialect) newOrderRequest(ctx context.Context, baseURL, orderID string) (*http.Request, error) {
+	return http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/api/orders/%s", baseURL, url.PathEscape(orderID)), nil)
+}
+
+func (gophermartDialect) notRegisteredStatus() int {
+	return http.StatusNoContent
+}
+
+func (gophermartDialect) decodeOrder(body []byte) (*models.AccrualOrderModel, error) {
+	var accrualOrderResponse models.AccrualOrderResponse
+	if err := json.Unmarshal(body, &accrualOrderResponse); err != nil {
+		return nil, err
+	}
+	return accrualOrderResponse.ToModel(), nil
+}
+
+func (gophermartDialect) supportsBatch() bool {
+	return true
+}
+
+type partnerState string
+
+const (
+	partnerStateAccepted    partnerState = "accepted"
+	partnerStateCalculating partnerState = "calculating"
+	partnerStateRejected    partnerState = "rejected"
+	partnerStateCompleted   partnerState = "completed"
+)
+
+var partnerStateToAccrualOrderStatus = map[partnerState]models.AccrualOrderStatus{
+	partnerStateAccepted:    models.AccrualOrderStatusRegistered,
+	partnerStateCalculating: models.AccrualOrderStatusProcessing,
+	partnerStateRejected:    models.AccrualOrderStatusInvalid,
+	partnerStateCompleted:   models.AccrualOrderStatusProcessed,
+}
+
+type partnerOrderResponse struct {
+	Number       string       `json:"number"`
+	State        partnerState `json:"state"`
+	BonusKopecks *int64       `json:"bonus_kopecks"`
+}
+
+// partnerDialect speaks the partner calculation service API: bearer token
+// auth, 404 for unknown orders and bonuses in kopecks.
+type partnerDialect struct {
+	token string
+}
+
+func (d partnerDialect) newOrderRequest(ctx context.Context, baseURL, orderID string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/v1/orders/%s", baseURL, url.PathEscape(orderID)), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+d.token)
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+func (partnerDialect) notRegisteredStatus() int {
+	return http.StatusNotFound
+}
+
+func (partnerDialect) decodeOrder(body []byte) (*models.AccrualOrderModel, error) {
+	var response partnerOrderResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+
+	status, ok := partnerStateToAccrualOrderStatus[response.State]
+	if !ok {
+		return nil, fmt.Errorf("unknown order state: %q", response.State)
+	}
+
+	return &models.AccrualOrderModel{
+		ID:      response.Number,
+		Status:  status,
+		Accrual: response.BonusKopecks,
+	}, nil
+}
+
+func (partnerDialect) supportsBatch() bool {
+	return false
+}
@@ -0,0 +1,77 @@
+package accrualclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartnerClient_GetOrder(t *testing.T) {
+	tests := []struct {
+		name           string
+		responseStatus int
+		responseBody   string
+		wantResult     *models.AccrualOrderModel
+		wantError      error
+	}{
+		{
+			name:           "completed",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"number":"123","state":"completed","bonus_kopecks":1050}`,
+			wantResult: &models.AccrualOrderModel{
+				ID:     "123",
+				Status: models.AccrualOrderStatusProcessed,
+				Accrual: func() *int64 {
+					v := int64(1050)
+					return &v
+				}(),
+			},
+		},
+		{
+			name:           "calculating",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"number":"123","state":"calculating"}`,
+			wantResult:     &models.AccrualOrderModel{ID: "123", Status: models.AccrualOrderStatusProcessing},
+		},
+		{
+			name:           "unknown state",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"number":"123","state":"lost"}`,
+			wantError:      ErrUnexpectedResponse,
+		},
+		{
+			name:           "not registered",
+			responseStatus: http.StatusNotFound,
+			wantError:      ErrOrderNotRegistered,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1/orders/123", r.URL.Path)
+				assert.Equal(t, "Bearer partner-token", r.Header.Get("Authorization"))
+				w.WriteHeader(tt.responseStatus)
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client := NewPartnerClient(server.URL, "partner-token", time.Second)
+
+			result, err := client.GetOrder(context.Background(), "123")
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantResult, result)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package accrualclient
+
+import "errors"
+
+// ErrOrderNotRegistered is returned when the accrual system answers 204 No
+// Content, i.e. it doesn't know the order yet.
+var ErrOrderNotRegistered = errors.New("order not registered in accrual system")
+
+// ErrAccrualUnavailable covers network failures and 5xx responses.
+var ErrAccrualUnavailable = errors.New("accrual system unavailable")
+
+// ErrUnexpectedResponse covers any other status code or a malformed body.
+var ErrUnexpectedResponse = errors.New("unexpected accrual system response")
+
+// ErrCircuitOpen is returned without calling the accrual system while the
+// circuit breaker is open.
+var ErrCircuitOpen = errors.New("accrual system circuit breaker is open")
+
+// ErrUnknownProvider is returned when a route refers to a provider missing
+// from the registry.
+var ErrUnknownProvider = errors.New("unknown accrual provider")
+
+// ErrLimiterUnavailable is returned when the shared rate limiter can't reach
+// its database. The accrual system wasn't called, so it says nothing about
+// the order or the accrual system.
+var ErrLimiterUnavailable = errors.New("shared rate limiter unavailable")
+
+// localError marks a failure that happened before a request reached the
+// accrual system, e.g. waiting on the limiter or building the request. It
+// says nothing about the accrual system's health, so the breaker ignores it.
+type localError struct {
+	err error
+}
+
+func (e *localError) Error() string { return e.err.Error() }
+
+func (e *localError) Unwrap() error { return e.err }
+
+func notSent(err error) error {
+	return &localError{err: err}
+}
@@ -8,5 +8,11 @@ import (
 
 type AccrualClienter interface {
 	IsRateLimited() bool
+	BreakerState() BreakerState
+	Ping(ctx context.Context) error
 	GetOrder(ctx context.Context, orderID string) (*models.AccrualOrderModel, error)
+	GetOrders(ctx context.Context, orderIDs []string) []OrderResult
+	// BatchSize is how many orders GetOrders looks up per request round, 0
+	// when it isn't bounded.
+	BatchSize(ctx context.Context) int
 }
@@ -0,0 +1,30 @@
+package accrualclient
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/metrics"
+)
+
+// metricsTransport records the latency and status code of every request to
+// the provider, including retries and batch lookups.
+type metricsTransport struct {
+	provider string
+	next     http.RoundTripper
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	metrics.AccrualRequestDuration.WithLabelValues(t.provider).Observe(time.Since(start).Seconds())
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	metrics.AccrualRequests.WithLabelValues(t.provider, status).Inc()
+
+	return resp, err
+}
@@ -0,0 +1,32 @@
+package accrualclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/etoneja/go-gophermart/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serverURL := server.URL
+
+	client := &http.Client{Transport: &metricsTransport{provider: "metrics-test", next: http.DefaultTransport}}
+
+	resp, err := client.Get(serverURL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	server.Close()
+	_, err = client.Get(serverURL)
+	require.Error(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.AccrualRequests.WithLabelValues("metrics-test", "204")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.AccrualRequests.WithLabelValues("metrics-test", "error")))
+}
@@ -0,0 +1,71 @@
+package accrualclient
+
+import (
+	"crypto/tls"
+	"time"
+)
+
+type Option func(*AccrualClient)
+
+// WithProviderName sets the provider label of the client metrics.
+func WithProviderName(name string) Option {
+	return func(c *AccrualClient) {
+		c.name = name
+	}
+}
+
+// WithRateLimiter replaces the default in-process limiter, e.g. with one
+// shared between replicas through NewSharedRateLimiter.
+func WithRateLimiter(l Limiter) Option {
+	return func(c *AccrualClient) {
+		c.rateLimiter = l
+	}
+}
+
+// WithRequestsPerMinute sets the initial rate of the default limiter.
+func WithRequestsPerMinute(perMinute int) Option {
+	return func(c *AccrualClient) {
+		c.rateLimiter = NewRateLimiter(perMinute)
+	}
+}
+
+// WithCircuitBreaker opens the breaker after threshold consecutive failures
+// for cooldown. A zero threshold disables the breaker.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *AccrualClient) {
+		c.breaker = newCircuitBreaker(threshold, cooldown)
+	}
+}
+
+// WithRetries retries requests failing with ErrAccrualUnavailable up to
+// maxRetries times with exponential backoff starting at baseDelay.
+func WithRetries(maxRetries int, baseDelay time.Duration) Option {
+	return func(c *AccrualClient) {
+		c.maxRetries = maxRetries
+		c.retryBaseDelay = baseDelay
+	}
+}
+
+// WithBatchConcurrency limits concurrent requests of GetOrders when the
+// accrual system has no batch endpoint.
+func WithBatchConcurrency(n int) Option {
+	return func(c *AccrualClient) {
+		c.batchConcurrency = n
+	}
+}
+
+// WithTLSConfig sets the TLS config of the connection to the accrual system,
+// see LoadTLSConfig for mutual TLS.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *AccrualClient) {
+		c.tlsConfig = tlsConfig
+	}
+}
+
+// WithRequestSigning signs every request with an HMAC of secret in the
+// X-Signature and X-Signature-Timestamp headers. An empty secret disables it.
+func WithRequestSigning(secret string) Option {
+	return func(c *AccrualClient) {
+		c.signingSecret = secret
+	}
+}
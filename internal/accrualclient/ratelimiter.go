@@ -1,14 +1,38 @@
 package accrualclient
 
 import (
+	"context"
+	"regexp"
+	"strconv"
 	"sync"
 	"time"
+
+	"github.com/etoneja/go-gophermart/internal/errs"
 )
 
+var rateLimitBodyRe = regexp.MustCompile(`No more than (\d+) requests per minute allowed`)
+
+// Limiter paces requests to the accrual system. It is implemented by
+// NewRateLimiter and NewSharedRateLimiter.
+type Limiter interface {
+	// wait blocks until a request may be sent. It fails fast with
+	// errs.ErrRateLimit while requests are blocked after a 429.
+	wait(ctx context.Context) error
+	// blockFor stops all requests for the given duration.
+	blockFor(ctx context.Context, duration time.Duration)
+	// setLimit retunes the sustained rate, zero disables pacing.
+	setLimit(ctx context.Context, perMinute int)
+	isBlocked() bool
+}
+
+// rateLimiter is an in-process token bucket with a burst of one request.
 type rateLimiter struct {
 	mu      sync.Mutex
 	blocked bool
 	until   time.Time
+	rate    float64
+	tokens  float64
+	last    time.Time
 }
 
 func (rl *rateLimiter) isBlocked() bool {
@@ -17,13 +41,82 @@ func (rl *rateLimiter) isBlocked() bool {
 	return rl.blocked && time.Now().Before(rl.until)
 }
 
-func (rl *rateLimiter) blockFor(duration time.Duration) {
+func (rl *rateLimiter) blockFor(_ context.Context, duration time.Duration) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 	rl.blocked = true
 	rl.until = time.Now().Add(duration)
 }
 
-func NewRateLimiter() *rateLimiter {
-	return &rateLimiter{}
+func (rl *rateLimiter) setLimit(_ context.Context, perMinute int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.rate = float64(perMinute) / 60
+}
+
+func (rl *rateLimiter) wait(ctx context.Context) error {
+	for {
+		if rl.isBlocked() {
+			return errs.ErrRateLimit
+		}
+
+		delay := rl.reserve(time.Now())
+		if delay == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token if one is available and otherwise returns how long
+// to wait for the next one.
+func (rl *rateLimiter) reserve(now time.Time) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if rl.rate <= 0 {
+		return 0
+	}
+
+	if rl.last.IsZero() {
+		rl.tokens = 1
+	} else {
+		rl.tokens = min(1, rl.tokens+now.Sub(rl.last).Seconds()*rl.rate)
+	}
+	rl.last = now
+
+	if rl.tokens >= 1 {
+		rl.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - rl.tokens) / rl.rate * float64(time.Second))
+}
+
+func NewRateLimiter(perMinute int) *rateLimiter {
+	rl := &rateLimiter{}
+	rl.setLimit(context.Background(), perMinute)
+	return rl
+}
+
+// parseRateLimitBody extracts N from the accrual system's
+// "No more than N requests per minute allowed" 429 body.
+func parseRateLimitBody(body []byte) (int, bool) {
+	match := rateLimitBodyRe.FindSubmatch(body)
+	if match == nil {
+		return 0, false
+	}
+
+	perMinute, err := strconv.Atoi(string(match[1]))
+	if err != nil || perMinute <= 0 {
+		return 0, false
+	}
+	return perMinute, true
 }
@@ -0,0 +1,187 @@
+package accrualclient
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/errs"
+	"github.com/etoneja/go-gophermart/internal/logging"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+const minSharedLimiterPoll = 10 * time.Millisecond
+
+// pgRateLimiter is a token bucket stored in Postgres so that all replicas
+// share one request budget. The row is refilled lazily on every acquire.
+type pgRateLimiter struct {
+	db        *pgxpool.Pool
+	name      string
+	perMinute int
+
+	logger zerolog.Logger
+
+	mu          sync.Mutex
+	until       time.Time
+	initialized bool
+}
+
+func NewSharedRateLimiter(db *pgxpool.Pool, name string, perMinute int, logger zerolog.Logger) *pgRateLimiter {
+	return &pgRateLimiter{
+		db:        db,
+		name:      name,
+		perMinute: perMinute,
+		logger:    logger,
+	}
+}
+
+// log returns the logger of the request or sync run in ctx, so limiter
+// warnings are tied to the call that hit them.
+func (rl *pgRateLimiter) log(ctx context.Context) *zerolog.Logger {
+	return logging.FromContext(ctx, &rl.logger)
+}
+
+// init creates the bucket row on first use; a configured rate overrides
+// whatever was auto-tuned before.
+func (rl *pgRateLimiter) init(ctx context.Context) error {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if rl.initialized {
+		return nil
+	}
+
+	query := `
+		INSERT INTO rate_limits (name, rate, tokens, updated_at)
+		VALUES ($1, $2, 1, NOW())
+		ON CONFLICT (name) DO UPDATE
+		SET rate = CASE WHEN EXCLUDED.rate > 0 THEN EXCLUDED.rate ELSE rate_limits.rate END
+	`
+
+	if _, err := rl.db.Exec(ctx, query, rl.name, float64(rl.perMinute)/60); err != nil {
+		return err
+	}
+
+	rl.initialized = true
+	return nil
+}
+
+func (rl *pgRateLimiter) isBlocked() bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return time.Now().Before(rl.until)
+}
+
+func (rl *pgRateLimiter) setBlockedUntil(until time.Time) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.until = until
+}
+
+func (rl *pgRateLimiter) blockFor(ctx context.Context, duration time.Duration) {
+	rl.setBlockedUntil(time.Now().Add(duration))
+
+	query := `
+		UPDATE rate_limits
+		SET blocked_until = GREATEST(COALESCE(blocked_until, NOW()), NOW() + make_interval(secs => $2))
+		WHERE name = $1
+	`
+
+	if _, err := rl.db.Exec(ctx, query, rl.name, duration.Seconds()); err != nil {
+		rl.log(ctx).Warn().Err(err).Str("limiter", rl.name).Msg("Failed to store shared rate limit block")
+	}
+}
+
+func (rl *pgRateLimiter) setLimit(ctx context.Context, perMinute int) {
+	query := `UPDATE rate_limits SET rate = $2 WHERE name = $1`
+
+	if _, err := rl.db.Exec(ctx, query, rl.name, float64(perMinute)/60); err != nil {
+		rl.log(ctx).Warn().Err(err).Str("limiter", rl.name).Msg("Failed to store shared rate limit")
+	}
+}
+
+func (rl *pgRateLimiter) wait(ctx context.Context) error {
+	for {
+		if rl.isBlocked() {
+			return errs.ErrRateLimit
+		}
+
+		if err := rl.init(ctx); err != nil {
+			return fmt.Errorf("%w: failed to init shared rate limit: %w", ErrLimiterUnavailable, err)
+		}
+
+		delay, blockedUntil, err := rl.reserve(ctx)
+		if err != nil {
+			return fmt.Errorf("%w: failed to acquire shared rate limit: %w", ErrLimiterUnavailable, err)
+		}
+		if blockedUntil != nil {
+			rl.setBlockedUntil(*blockedUntil)
+			return errs.ErrRateLimit
+		}
+		if delay == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(max(delay, minSharedLimiterPoll))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve atomically refills the bucket and takes a token. When none is
+// available it returns the expected wait, or the block deadline after a 429.
+func (rl *pgRateLimiter) reserve(ctx context.Context) (time.Duration, *time.Time, error) {
+	query := `
+		WITH bucket AS (
+			SELECT
+				name,
+				rate,
+				blocked_until,
+				CASE
+					WHEN rate <= 0 THEN 1
+					ELSE LEAST(1, tokens + EXTRACT(EPOCH FROM (NOW() - updated_at))::DOUBLE PRECISION * rate)
+				END AS available
+			FROM rate_limits
+			WHERE name = $1
+			FOR UPDATE
+		)
+		UPDATE rate_limits AS rl
+		SET
+			tokens = CASE
+				WHEN bucket.blocked_until > NOW() THEN bucket.available
+				WHEN bucket.available >= 1 THEN bucket.available - 1
+				ELSE bucket.available
+			END,
+			updated_at = NOW()
+		FROM bucket
+		WHERE rl.name = bucket.name
+		RETURNING
+			bucket.available >= 1 AND (bucket.blocked_until IS NULL OR bucket.blocked_until <= NOW()),
+			CASE WHEN bucket.blocked_until > NOW() THEN bucket.blocked_until END,
+			CASE WHEN bucket.rate > 0 THEN (1 - bucket.available) / bucket.rate ELSE 0 END
+	`
+
+	var (
+		acquired     bool
+		blockedUntil *time.Time
+		waitSeconds  float64
+	)
+	err := rl.db.QueryRow(ctx, query, rl.name).Scan(&acquired, &blockedUntil, &waitSeconds)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if blockedUntil != nil {
+		return 0, blockedUntil, nil
+	}
+	if acquired {
+		return 0, nil, nil
+	}
+	return time.Duration(waitSeconds * float64(time.Second)), nil, nil
+}
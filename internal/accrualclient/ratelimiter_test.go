@@ -0,0 +1,67 @@
+package accrualclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Reserve(t *testing.T) {
+	rl := NewRateLimiter(60)
+	now := time.Now()
+
+	assert.Zero(t, rl.reserve(now))
+	assert.Equal(t, time.Second, rl.reserve(now))
+	assert.Equal(t, 500*time.Millisecond, rl.reserve(now.Add(500*time.Millisecond)))
+	assert.Zero(t, rl.reserve(now.Add(time.Second)))
+}
+
+func TestRateLimiter_Unlimited(t *testing.T) {
+	rl := NewRateLimiter(0)
+	now := time.Now()
+
+	for range 100 {
+		assert.Zero(t, rl.reserve(now))
+	}
+}
+
+func TestRateLimiter_WaitBlocked(t *testing.T) {
+	rl := NewRateLimiter(0)
+	rl.blockFor(context.Background(), time.Minute)
+
+	err := rl.wait(context.Background())
+	assert.ErrorIs(t, err, errs.ErrRateLimit)
+}
+
+func TestParseRateLimitBody(t *testing.T) {
+	perMinute, ok := parseRateLimitBody([]byte("No more than 60 requests per minute allowed"))
+	assert.True(t, ok)
+	assert.Equal(t, 60, perMinute)
+
+	_, ok = parseRateLimitBody([]byte("Too Many Requests"))
+	assert.False(t, ok)
+}
+
+func TestAccrualClient_TooManyRequestsTunesLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte("No more than 30 requests per minute allowed"))
+	}))
+	defer server.Close()
+
+	rl := NewRateLimiter(0)
+	client := NewAccrualClient(server.URL, time.Second, WithRateLimiter(rl))
+
+	_, err := client.GetOrder(context.Background(), "123")
+	require.ErrorIs(t, err, errs.ErrRateLimit)
+
+	assert.True(t, client.IsRateLimited())
+	assert.Equal(t, 0.5, rl.rate)
+}
@@ -0,0 +1,156 @@
+package accrualclient
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/etoneja/go-gophermart/internal/models"
+)
+
+const (
+	DefaultProvider = "default"
+	PartnerProvider = "partner"
+)
+
+// Route sends orders whose number starts with Prefix to Provider.
+type Route struct {
+	Prefix   string
+	Provider string
+}
+
+// Registry is an AccrualClienter routing every order to a named provider by
+// the longest matching number prefix. Orders matching no route go to the
+// DefaultProvider.
+type Registry struct {
+	providers map[string]AccrualClienter
+	routes    []Route
+}
+
+func NewRegistry(defaultProvider AccrualClienter) *Registry {
+	return &Registry{
+		providers: map[string]AccrualClienter{DefaultProvider: defaultProvider},
+	}
+}
+
+func (r *Registry) Register(name string, provider AccrualClienter) {
+	r.providers[name] = provider
+}
+
+func (r *Registry) AddRoute(route Route) error {
+	if _, ok := r.providers[route.Provider]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownProvider, route.Provider)
+	}
+
+	r.routes = append(r.routes, route)
+	slices.SortStableFunc(r.routes, func(a, b Route) int {
+		return cmp.Compare(len(b.Prefix), len(a.Prefix))
+	})
+	return nil
+}
+
+func (r *Registry) providerName(orderID string) string {
+	for _, route := range r.routes {
+		if strings.HasPrefix(orderID, route.Prefix) {
+			return route.Provider
+		}
+	}
+	return DefaultProvider
+}
+
+// IsRateLimited reports whether every provider is rate limited, lookups
+// routed to a limited provider fail fast with errs.ErrRateLimit on their own.
+func (r *Registry) IsRateLimited() bool {
+	for _, provider := range r.providers {
+		if !provider.IsRateLimited() {
+			return false
+		}
+	}
+	return true
+}
+
+// BreakerState is open when every provider's breaker is open, closed when
+// every one is closed and half-open otherwise.
+func (r *Registry) BreakerState() BreakerState {
+	var open, closed int
+	for _, provider := range r.providers {
+		switch provider.BreakerState() {
+		case BreakerStateOpen:
+			open++
+		case BreakerStateClosed:
+			closed++
+		}
+	}
+
+	switch len(r.providers) {
+	case open:
+		return BreakerStateOpen
+	case closed:
+		return BreakerStateClosed
+	default:
+		return BreakerStateHalfOpen
+	}
+}
+
+// Ping pings every provider and joins the errors of unreachable ones.
+func (r *Registry) Ping(ctx context.Context) error {
+	var errs []error
+	for name, provider := range r.providers {
+		if err := provider.Ping(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Registry) GetOrder(ctx context.Context, orderID string) (*models.AccrualOrderModel, error) {
+	return r.providers[r.providerName(orderID)].GetOrder(ctx, orderID)
+}
+
+// GetOrders splits the orders by provider and looks the groups up
+// concurrently, results keep the order of orderIDs.
+func (r *Registry) GetOrders(ctx context.Context, orderIDs []string) []OrderResult {
+	groups := make(map[string][]int)
+	for i, orderID := range orderIDs {
+		name := r.providerName(orderID)
+		groups[name] = append(groups[name], i)
+	}
+
+	results := make([]OrderResult, len(orderIDs))
+
+	var wg sync.WaitGroup
+	for name, indexes := range groups {
+		wg.Add(1)
+		go func(provider AccrualClienter, indexes []int) {
+			defer wg.Done()
+
+			ids := make([]string, 0, len(indexes))
+			for _, i := range indexes {
+				ids = append(ids, orderIDs[i])
+			}
+
+			for j, result := range provider.GetOrders(ctx, ids) {
+				results[indexes[j]] = result
+			}
+		}(r.providers[name], indexes)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// BatchSize is the smallest bounded batch size of the providers, so a batch
+// fits every provider it may be routed to.
+func (r *Registry) BatchSize(ctx context.Context) int {
+	size := 0
+	for _, provider := range r.providers {
+		if n := provider.BatchSize(ctx); n > 0 && (size == 0 || n < size) {
+			size = n
+		}
+	}
+	return size
+}
@@ -0,0 +1,77 @@
+package accrualclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Routing(t *testing.T) {
+	defaultAccrual, partnerAccrual := int64(100), int64(200)
+
+	registry := NewRegistry(NewStaticProvider(
+		StaticRule{Status: models.AccrualOrderStatusProcessed, Accrual: &defaultAccrual},
+	))
+	registry.Register(PartnerProvider, NewStaticProvider(
+		StaticRule{Prefix: "4", Status: models.AccrualOrderStatusProcessed, Accrual: &partnerAccrual},
+	))
+
+	require.NoError(t, registry.AddRoute(Route{Prefix: "4", Provider: PartnerProvider}))
+	require.NoError(t, registry.AddRoute(Route{Prefix: "42", Provider: DefaultProvider}))
+	assert.ErrorIs(t, registry.AddRoute(Route{Prefix: "5", Provider: "unknown"}), ErrUnknownProvider)
+
+	tests := []struct {
+		orderID     string
+		wantAccrual int64
+	}{
+		{orderID: "12345678903", wantAccrual: defaultAccrual},
+		{orderID: "4561261212345467", wantAccrual: partnerAccrual},
+		{orderID: "4242424242424242", wantAccrual: defaultAccrual},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.orderID, func(t *testing.T) {
+			order, err := registry.GetOrder(context.Background(), tt.orderID)
+			require.NoError(t, err)
+			require.NotNil(t, order.Accrual)
+			assert.Equal(t, tt.wantAccrual, *order.Accrual)
+		})
+	}
+
+	ids := make([]string, 0, len(tests))
+	for _, tt := range tests {
+		ids = append(ids, tt.orderID)
+	}
+
+	results := registry.GetOrders(context.Background(), ids)
+	require.Len(t, results, len(tests))
+	for i, result := range results {
+		assert.Equal(t, tests[i].orderID, result.ID)
+		require.NoError(t, result.Err)
+		assert.Equal(t, tests[i].wantAccrual, *result.Order.Accrual)
+	}
+}
+
+func TestRegistry_BatchSize(t *testing.T) {
+	registry := NewRegistry(NewStaticProvider())
+	assert.Zero(t, registry.BatchSize(context.Background()))
+
+	registry.Register(PartnerProvider, NewPartnerClient("http://partner.invalid", "token", time.Second, WithBatchConcurrency(3)))
+	assert.Equal(t, 3, registry.BatchSize(context.Background()))
+}
+
+func TestStaticProvider_NotRegistered(t *testing.T) {
+	provider := NewStaticProvider(StaticRule{Prefix: "1", Status: models.AccrualOrderStatusProcessing})
+
+	_, err := provider.GetOrder(context.Background(), "79927398713")
+	assert.ErrorIs(t, err, ErrOrderNotRegistered)
+
+	order, err := provider.GetOrder(context.Background(), "12345678903")
+	require.NoError(t, err)
+	assert.Equal(t, models.AccrualOrderStatusProcessing, order.Status)
+	assert.Nil(t, order.Accrual)
+}
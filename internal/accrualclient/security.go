@@ -0,0 +1,101 @@
+package accrualclient
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/utils"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// LoadTLSConfig builds a client TLS config from PEM files. certFile and
+// keyFile enable mutual TLS, caFile replaces the system roots. Empty paths
+// are skipped, a nil config is returned when all of them are empty.
+func LoadTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	if certFile == "" && keyFile == "" && caFile == "" {
+		return nil, nil
+	}
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("client certificate and key must be set together")
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if caFile != "" {
+		caPEM, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+// signingTransport signs every request with utils.SignPayload over
+// utils.RequestSigningPayload.
+type signingTransport struct {
+	secret string
+	next   http.RoundTripper
+}
+
+func (t *signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	signed := req.Clone(req.Context())
+	signed.Body = io.NopCloser(bytes.NewReader(body))
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	payload := utils.RequestSigningPayload(req.Method, req.URL.RequestURI(), body)
+	signed.Header.Set(utils.SignatureTimestampHeader, timestamp)
+	signed.Header.Set(utils.SignatureHeader, utils.SignPayload(t.secret, timestamp, payload))
+
+	return t.next.RoundTrip(signed)
+}
+
+func (c *AccrualClient) buildTransport() {
+	var transport http.RoundTripper = http.DefaultTransport
+	if c.tlsConfig != nil {
+		base := http.DefaultTransport.(*http.Transport).Clone()
+		base.TLSClientConfig = c.tlsConfig
+		transport = base
+	}
+	if c.signingSecret != "" {
+		transport = &signingTransport{secret: c.signingSecret, next: transport}
+	}
+	transport = &metricsTransport{provider: c.name, next: transport}
+	transport = otelhttp.NewTransport(transport,
+		otelhttp.WithSpanNameFormatter(func(_ string, req *http.Request) string {
+			return "accrual." + c.name + " " + req.Method
+		}))
+
+	c.client.Transport = transport
+}
@@ -0,0 +1,176 @@
+package accrualclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newTestCert(t *testing.T, template *x509.Certificate, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	signerCert, signerKey := template, key
+	if parent != nil {
+		signerCert, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func (c *testCert) writePEM(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+
+	certFile := filepath.Join(dir, name+".crt")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600))
+
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	keyFile := filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return certFile, keyFile
+}
+
+func TestAccrualClient_MutualTLS(t *testing.T) {
+	notAfter := time.Now().Add(time.Hour)
+	ca := newTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	serverCert := newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "accrual"},
+		NotAfter:     notAfter,
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+	clientCert := newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "gophermart"},
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+
+	dir := t.TempDir()
+	caFile, _ := ca.writePEM(t, dir, "ca")
+	certFile, keyFile := clientCert.writePEM(t, dir, "client")
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"order":"123","status":"PROCESSING"}`))
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{serverCert.der}, PrivateKey: serverCert.key}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	t.Run("with client certificate", func(t *testing.T) {
+		tlsConfig, err := LoadTLSConfig(certFile, keyFile, caFile)
+		require.NoError(t, err)
+
+		client := NewAccrualClient(server.URL, time.Second, WithTLSConfig(tlsConfig))
+		_, err = client.GetOrder(context.Background(), "123")
+		assert.NoError(t, err)
+	})
+
+	t.Run("without client certificate", func(t *testing.T) {
+		tlsConfig, err := LoadTLSConfig("", "", caFile)
+		require.NoError(t, err)
+
+		client := NewAccrualClient(server.URL, time.Second, WithTLSConfig(tlsConfig), WithRetries(0, 0))
+		_, err = client.GetOrder(context.Background(), "123")
+		assert.ErrorIs(t, err, ErrAccrualUnavailable)
+	})
+}
+
+func TestLoadTLSConfig_Invalid(t *testing.T) {
+	_, err := LoadTLSConfig("client.crt", "", "")
+	assert.Error(t, err)
+
+	_, err = LoadTLSConfig("", "", filepath.Join(t.TempDir(), "missing.pem"))
+	assert.Error(t, err)
+
+	tlsConfig, err := LoadTLSConfig("", "", "")
+	assert.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+}
+
+func TestAccrualClient_RequestSigning(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		payload := utils.RequestSigningPayload(r.Method, r.URL.RequestURI(), body)
+		timestamp := r.Header.Get(utils.SignatureTimestampHeader)
+		if !utils.VerifySignature("secret", timestamp, payload, r.Header.Get(utils.SignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"order":"123","status":"PROCESSING"}`))
+	}))
+	defer server.Close()
+
+	client := NewAccrualClient(server.URL, time.Second, WithRequestSigning("secret"))
+	_, err := client.GetOrder(context.Background(), "123")
+	assert.NoError(t, err)
+
+	client = NewAccrualClient(server.URL, time.Second, WithRequestSigning("wrong"))
+	_, err = client.GetOrder(context.Background(), "123")
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+}
+
+func TestPartnerClient_RequestSigning(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := utils.RequestSigningPayload(r.Method, r.URL.RequestURI(), nil)
+		timestamp := r.Header.Get(utils.SignatureTimestampHeader)
+		if !utils.VerifySignature("partner-secret", timestamp, payload, r.Header.Get(utils.SignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"number":"123","state":"calculating"}`))
+	}))
+	defer server.Close()
+
+	client := NewPartnerClient(server.URL, "partner-token", time.Second, WithRequestSigning("partner-secret"))
+	_, err := client.GetOrder(context.Background(), "123")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,67 @@
+package accrualclient
+
+import (
+	"context"
+	"strings"
+
+	"github.com/etoneja/go-gophermart/internal/models"
+)
+
+// StaticRule answers lookups of orders starting with Prefix, an empty prefix
+// matches every order.
+type StaticRule struct {
+	Prefix  string
+	Status  models.AccrualOrderStatus
+	Accrual *int64
+}
+
+// StaticProvider answers from fixed rules without any network calls, orders
+// matching no rule are not registered. It is meant for tests.
+type StaticProvider struct {
+	rules []StaticRule
+}
+
+func NewStaticProvider(rules ...StaticRule) *StaticProvider {
+	return &StaticProvider{rules: rules}
+}
+
+func (p *StaticProvider) IsRateLimited() bool {
+	return false
+}
+
+func (p *StaticProvider) BreakerState() BreakerState {
+	return BreakerStateClosed
+}
+
+func (p *StaticProvider) Ping(context.Context) error {
+	return nil
+}
+
+func (p *StaticProvider) GetOrder(_ context.Context, orderID string) (*models.AccrualOrderModel, error) {
+	for _, rule := range p.rules {
+		if !strings.HasPrefix(orderID, rule.Prefix) {
+			continue
+		}
+
+		order := &models.AccrualOrderModel{ID: orderID, Status: rule.Status}
+		if rule.Accrual != nil {
+			accrual := *rule.Accrual
+			order.Accrual = &accrual
+		}
+		return order, nil
+	}
+	return nil, ErrOrderNotRegistered
+}
+
+func (p *StaticProvider) GetOrders(ctx context.Context, orderIDs []string) []OrderResult {
+	results := make([]OrderResult, 0, len(orderIDs))
+	for _, orderID := range orderIDs {
+		order, err := p.GetOrder(ctx, orderID)
+		results = append(results, OrderResult{ID: orderID, Order: order, Err: err})
+	}
+	return results
+}
+
+func (p *StaticProvider) BatchSize(context.Context) int {
+	return 0
+}
@@ -0,0 +1,74 @@
+package accrualsim
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/etoneja/go-gophermart/internal/models"
+)
+
+type RewardType string
+
+const (
+	RewardTypePercent RewardType = "%"
+	RewardTypePoints  RewardType = "pt"
+)
+
+var (
+	ErrInvalidRule = errors.New("invalid reward rule")
+	ErrOrderExists = errors.New("order already registered")
+)
+
+// RewardRule grants a reward for every good whose description contains Match.
+// Reward is a percent of the price for RewardTypePercent and a fixed amount
+// in rubles for RewardTypePoints.
+type RewardRule struct {
+	Match      string     `json:"match"`
+	Reward     float64    `json:"reward"`
+	RewardType RewardType `json:"reward_type"`
+}
+
+func (r *RewardRule) Validate() error {
+	if strings.TrimSpace(r.Match) == "" {
+		return errors.Join(ErrInvalidRule, errors.New("match is required"))
+	}
+	if r.Reward <= 0 || math.IsInf(r.Reward, 0) || math.IsNaN(r.Reward) {
+		return errors.Join(ErrInvalidRule, errors.New("reward must be positive"))
+	}
+	if r.RewardType != RewardTypePercent && r.RewardType != RewardTypePoints {
+		return errors.Join(ErrInvalidRule, errors.New("reward_type must be % or pt"))
+	}
+	return nil
+}
+
+func (r *RewardRule) matches(good Good) bool {
+	return strings.Contains(strings.ToLower(good.Description), strings.ToLower(r.Match))
+}
+
+func (r *RewardRule) rewardFor(good Good) int64 {
+	if r.RewardType == RewardTypePercent {
+		return int64(math.Round(float64(good.Price.Kopecks()) * r.Reward / 100))
+	}
+	return int64(math.Round(r.Reward * 100))
+}
+
+type Good struct {
+	Description string       `json:"description"`
+	Price       models.Money `json:"price"`
+}
+
+// calculateAccrual applies the first matching rule to every good, goods
+// without a matching rule earn nothing.
+func calculateAccrual(rules []RewardRule, goods []Good) int64 {
+	var total int64
+	for _, good := range goods {
+		for i := range rules {
+			if rules[i].matches(good) {
+				total += rules[i].rewardFor(good)
+				break
+			}
+		}
+	}
+	return total
+}
@@ -0,0 +1,317 @@
+// Package accrualsim implements a stand-in for the external accrual system,
+// for local development and tests.
+package accrualsim
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/models"
+	"github.com/etoneja/go-gophermart/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type Config struct {
+	Rules []RewardRule
+	// AutoRegister registers unknown orders on their first GET instead of
+	// requiring POST /api/orders. Such orders earn DefaultAccrual.
+	AutoRegister   bool
+	DefaultAccrual models.Money
+	// RegisterDelay is how long an auto-registered order answers 204.
+	RegisterDelay time.Duration
+	// StepDuration is how long an order stays REGISTERED and then PROCESSING.
+	StepDuration time.Duration
+	MinDelay     time.Duration
+	MaxDelay     time.Duration
+	// RateLimit is the number of lookups allowed per minute, 0 disables
+	// throttling. A batch lookup counts as one.
+	RateLimit int
+	// BatchSize enables the batch lookup endpoint with at most BatchSize
+	// orders per request.
+	BatchSize int
+}
+
+type order struct {
+	goods        []Good
+	accrual      *int64
+	registeredAt time.Time
+}
+
+type Simulator struct {
+	cfg Config
+	now func() time.Time
+
+	mu          sync.Mutex
+	rules       []RewardRule
+	orders      map[string]*order
+	windowStart time.Time
+	windowCount int
+}
+
+func NewSimulator(cfg Config) *Simulator {
+	return &Simulator{
+		cfg:    cfg,
+		now:    time.Now,
+		rules:  append([]RewardRule(nil), cfg.Rules...),
+		orders: make(map[string]*order),
+	}
+}
+
+// NewServer starts the simulator on a local httptest server, the caller is
+// responsible for closing it.
+func NewServer(cfg Config) (*Simulator, *httptest.Server) {
+	sim := NewSimulator(cfg)
+	return sim, httptest.NewServer(sim.Handler())
+}
+
+func (s *Simulator) Handler() http.Handler {
+	router := gin.New()
+	router.Use(gin.Recovery())
+
+	router.GET("/api/orders/:number", s.getOrderHandler)
+	router.POST("/api/orders", s.registerOrderHandler)
+	router.POST("/api/goods", s.createRuleHandler)
+
+	if s.cfg.BatchSize > 0 {
+		router.GET("/api/capabilities", s.capabilitiesHandler)
+		router.POST("/api/orders/batch", s.getOrdersBatchHandler)
+	}
+
+	return router
+}
+
+func (s *Simulator) getOrderHandler(c *gin.Context) {
+	if !s.sleep(c) {
+		return
+	}
+
+	if s.rejectThrottled(c) {
+		return
+	}
+
+	response, ok := s.orderStatus(c.Param("number"))
+	if !ok {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (s *Simulator) capabilitiesHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"batch_endpoint": "/api/orders/batch",
+		"max_batch_size": s.cfg.BatchSize,
+	})
+}
+
+type batchRequest struct {
+	Orders []string `json:"orders" binding:"required"`
+}
+
+// getOrdersBatchHandler answers with the statuses of registered orders only,
+// the batch counterpart of a 204 is leaving the order out.
+func (s *Simulator) getOrdersBatchHandler(c *gin.Context) {
+	var req batchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Orders) > s.cfg.BatchSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("no more than %d orders per batch", s.cfg.BatchSize)})
+		return
+	}
+
+	if !s.sleep(c) {
+		return
+	}
+
+	if s.rejectThrottled(c) {
+		return
+	}
+
+	responses := make([]*models.AccrualOrderResponse, 0, len(req.Orders))
+	for _, number := range req.Orders {
+		if response, ok := s.orderStatus(number); ok {
+			responses = append(responses, response)
+		}
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+type registerOrderRequest struct {
+	Order string `json:"order" binding:"required"`
+	Goods []Good `json:"goods"`
+}
+
+func (s *Simulator) registerOrderHandler(c *gin.Context) {
+	var req registerOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.RegisterOrder(req.Order, req.Goods); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// RegisterOrder registers an order the way POST /api/orders does, its accrual
+// is calculated from the reward rules.
+func (s *Simulator) RegisterOrder(number string, goods []Good) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.orders[number]; exists {
+		return ErrOrderExists
+	}
+
+	s.orders[number] = &order{goods: goods, registeredAt: s.now()}
+	return nil
+}
+
+func (s *Simulator) createRuleHandler(c *gin.Context) {
+	var rule RewardRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.AddRule(rule); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (s *Simulator) AddRule(rule RewardRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.rules {
+		if existing.Match == rule.Match {
+			return fmt.Errorf("%w: match %q already exists", ErrInvalidRule, rule.Match)
+		}
+	}
+	s.rules = append(s.rules, rule)
+	return nil
+}
+
+// sleep waits a random delay between MinDelay and MaxDelay, it returns false
+// if the client went away in the meantime.
+func (s *Simulator) sleep(c *gin.Context) bool {
+	delay := s.cfg.MinDelay
+	if spread := s.cfg.MaxDelay - s.cfg.MinDelay; spread > 0 {
+		delay += rand.N(spread)
+	}
+	if delay <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-c.Request.Context().Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// rejectThrottled answers 429 with Retry-After once the rate limit is hit.
+func (s *Simulator) rejectThrottled(c *gin.Context) bool {
+	retryAfter, limited := s.throttle()
+	if !limited {
+		return false
+	}
+
+	c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+	c.String(http.StatusTooManyRequests, "No more than %d requests per minute allowed", s.cfg.RateLimit)
+	return true
+}
+
+// throttle counts requests in fixed one-minute windows and reports how long
+// to wait once the window is exhausted.
+func (s *Simulator) throttle() (time.Duration, bool) {
+	if s.cfg.RateLimit <= 0 {
+		return 0, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.windowStart) >= time.Minute {
+		s.windowStart = now
+		s.windowCount = 0
+	}
+
+	if s.windowCount >= s.cfg.RateLimit {
+		retryAfter := s.windowStart.Add(time.Minute).Sub(now).Round(time.Second)
+		return max(retryAfter, time.Second), true
+	}
+
+	s.windowCount++
+	return 0, false
+}
+
+func (s *Simulator) orderStatus(number string) (*models.AccrualOrderResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	o, exists := s.orders[number]
+	if !exists {
+		if !s.cfg.AutoRegister {
+			return nil, false
+		}
+		accrual := s.cfg.DefaultAccrual.Kopecks()
+		o = &order{accrual: &accrual, registeredAt: now.Add(s.cfg.RegisterDelay)}
+		s.orders[number] = o
+	}
+
+	if now.Before(o.registeredAt) {
+		return nil, false
+	}
+
+	response := &models.AccrualOrderResponse{Order: number}
+
+	elapsed := now.Sub(o.registeredAt)
+	switch {
+	case elapsed < s.cfg.StepDuration:
+		response.Status = models.AccrualOrderStatusRegistered
+	case elapsed < 2*s.cfg.StepDuration:
+		response.Status = models.AccrualOrderStatusProcessing
+	default:
+		if valid, _ := utils.LuhnCheck(number); !valid {
+			response.Status = models.AccrualOrderStatusInvalid
+			break
+		}
+
+		accrual := calculateAccrual(s.rules, o.goods)
+		if o.accrual != nil {
+			accrual = *o.accrual
+		}
+		money := models.Money(accrual)
+		response.Status = models.AccrualOrderStatusProcessed
+		response.Accrual = &money
+	}
+
+	return response, true
+}
@@ -0,0 +1,170 @@
+package accrualsim
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestServer(t *testing.T, cfg Config) (string, *fakeClock) {
+	t.Helper()
+	sim, server := NewServer(cfg)
+	t.Cleanup(server.Close)
+
+	clock := &fakeClock{t: time.Now()}
+	sim.now = clock.now
+	return server.URL, clock
+}
+
+func getOrder(t *testing.T, baseURL, number string) (*http.Response, *models.AccrualOrderResponse) {
+	t.Helper()
+	resp, err := http.Get(baseURL + "/api/orders/" + number)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	var body models.AccrualOrderResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	return resp, &body
+}
+
+func postJSON(t *testing.T, url string, body any) int {
+	t.Helper()
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	resp, err := http.Post(url, "application/json", bytes.NewReader(data))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode
+}
+
+func TestSimulator_Progression(t *testing.T) {
+	baseURL, clock := newTestServer(t, Config{
+		AutoRegister:   true,
+		DefaultAccrual: 50000,
+		RegisterDelay:  time.Second,
+		StepDuration:   time.Second,
+	})
+
+	resp, _ := getOrder(t, baseURL, "12345678903")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	steps := []models.AccrualOrderStatus{
+		models.AccrualOrderStatusRegistered,
+		models.AccrualOrderStatusProcessing,
+		models.AccrualOrderStatusProcessed,
+	}
+	for _, want := range steps {
+		clock.advance(time.Second)
+		resp, body := getOrder(t, baseURL, "12345678903")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, want, body.Status)
+	}
+
+	_, body := getOrder(t, baseURL, "12345678903")
+	require.NotNil(t, body.Accrual)
+	assert.Equal(t, models.Money(50000), *body.Accrual)
+}
+
+func TestSimulator_InvalidOrder(t *testing.T) {
+	baseURL, _ := newTestServer(t, Config{AutoRegister: true})
+
+	_, body := getOrder(t, baseURL, "12345678900")
+	require.NotNil(t, body)
+	assert.Equal(t, models.AccrualOrderStatusInvalid, body.Status)
+	assert.Nil(t, body.Accrual)
+}
+
+func TestSimulator_RegisteredOrderRules(t *testing.T) {
+	baseURL, _ := newTestServer(t, Config{
+		Rules: []RewardRule{{Match: "Bork", Reward: 10, RewardType: RewardTypePercent}},
+	})
+
+	resp, _ := getOrder(t, baseURL, "12345678903")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode, "unknown orders are not auto-registered")
+
+	assert.Equal(t, http.StatusOK, postJSON(t, baseURL+"/api/goods",
+		RewardRule{Match: "LG", Reward: 5, RewardType: RewardTypePoints}))
+	assert.Equal(t, http.StatusConflict, postJSON(t, baseURL+"/api/goods",
+		RewardRule{Match: "LG", Reward: 5, RewardType: RewardTypePoints}))
+
+	order := registerOrderRequest{
+		Order: "12345678903",
+		Goods: []Good{
+			{Description: "Чайник Bork", Price: 700000},
+			{Description: "Телевизор LG", Price: 5000000},
+			{Description: "Стул", Price: 100000},
+		},
+	}
+	assert.Equal(t, http.StatusAccepted, postJSON(t, baseURL+"/api/orders", order))
+	assert.Equal(t, http.StatusConflict, postJSON(t, baseURL+"/api/orders", order))
+
+	_, body := getOrder(t, baseURL, "12345678903")
+	require.NotNil(t, body.Accrual)
+	assert.Equal(t, models.Money(70000+500), *body.Accrual)
+}
+
+func TestSimulator_Throttling(t *testing.T) {
+	baseURL, clock := newTestServer(t, Config{AutoRegister: true, RateLimit: 2})
+
+	for range 2 {
+		resp, _ := getOrder(t, baseURL, "12345678903")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	clock.advance(20 * time.Second)
+	resp, err := http.Get(baseURL + "/api/orders/12345678903")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "40", resp.Header.Get("Retry-After"))
+	assert.Equal(t, "No more than 2 requests per minute allowed", string(body))
+
+	clock.advance(40 * time.Second)
+	resp, _ = getOrder(t, baseURL, "12345678903")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestRewardRule_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    RewardRule
+		wantErr bool
+	}{
+		{name: "percent", rule: RewardRule{Match: "Bork", Reward: 10, RewardType: RewardTypePercent}},
+		{name: "points", rule: RewardRule{Match: "Bork", Reward: 10, RewardType: RewardTypePoints}},
+		{name: "empty match", rule: RewardRule{Reward: 10, RewardType: RewardTypePoints}, wantErr: true},
+		{name: "zero reward", rule: RewardRule{Match: "Bork", RewardType: RewardTypePoints}, wantErr: true},
+		{name: "unknown type", rule: RewardRule{Match: "Bork", Reward: 10, RewardType: "x"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidRule)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -4,52 +4,41 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/etoneja/go-gophermart/internal/config"
-	"github.com/etoneja/go-gophermart/internal/db"
 	"github.com/etoneja/go-gophermart/internal/handlers"
+	"github.com/etoneja/go-gophermart/internal/health"
 	"github.com/etoneja/go-gophermart/internal/middlewares"
 	"github.com/etoneja/go-gophermart/internal/service"
+	"github.com/etoneja/go-gophermart/internal/tracing"
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 type APIApp struct {
 	Config *config.Config
-	DB     *pgxpool.Pool
 	Router *gin.Engine
 	Server *http.Server
-}
-
-func NewAPIApp(ctx context.Context, logger zerolog.Logger) (*APIApp, error) {
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load config: %w", err)
-	}
-
-	if cfg.Debug {
-		gin.SetMode(gin.DebugMode)
-	} else {
-		gin.SetMode(gin.ReleaseMode)
-	}
 
-	dbPool, err := db.NewDB(ctx, cfg.DatabaseURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
-	}
-
-	migrator := db.NewMigrator(dbPool, logger)
-	if err := migrator.Migrate(ctx); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
-	}
-
-	svc := service.NewService(cfg, dbPool, logger)
+	health *health.Checker
+}
 
+func NewAPIApp(cfg *config.Config, svc service.Servicer, checker *health.Checker, logger zerolog.Logger) *APIApp {
 	mws := middlewares.NewMiddlewares(svc, logger)
 
 	router := gin.New()
 	router.Use(gin.Recovery())
+	router.Use(otelgin.Middleware(tracing.ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+		switch r.URL.Path {
+		case "/healthz", "/readyz":
+			return false
+		}
+		return true
+	})))
+
+	router.Use(mws.RequestIDMiddleware())
 
 	if cfg.Debug {
 		router.Use(mws.DebugLoggingMiddleware())
@@ -59,6 +48,9 @@ func NewAPIApp(ctx context.Context, logger zerolog.Logger) (*APIApp, error) {
 
 	hs := handlers.NewHandlers(svc, logger)
 
+	router.GET("/healthz", gin.WrapH(checker.LivenessHandler()))
+	router.GET("/readyz", gin.WrapH(checker.ReadinessHandler()))
+
 	apiGroup := router.Group("/api/user")
 	{
 		apiGroup.POST("/register", hs.RegisterUserHandler)
@@ -70,34 +62,72 @@ func NewAPIApp(ctx context.Context, logger zerolog.Logger) (*APIApp, error) {
 			privateGroup.POST("/orders", hs.CreateOrderHandler)
 			privateGroup.GET("/orders", hs.GetOrdersHandler)
 			privateGroup.GET("/balance", hs.GetBalanceHandler)
+			privateGroup.GET("/balances", hs.GetBalancesHandler)
 			privateGroup.POST("/balance/withdraw", hs.CreateWithdrawHandler)
+			privateGroup.POST("/balance/transfer", hs.CreateTransferHandler)
+			privateGroup.POST("/balance/convert", hs.ConvertBalanceHandler)
 			privateGroup.GET("/withdrawals", hs.GetWithdrawalsHandler)
+			privateGroup.GET("/tier", hs.GetTierHandler)
+			privateGroup.GET("/referrals", hs.GetReferralsHandler)
 		}
 	}
 
+	adminGroup := router.Group("/api/admin")
+	adminGroup.Use(mws.AdminMiddleware(cfg.AdminToken))
+	{
+		adminGroup.POST("/campaigns", hs.CreateCampaignHandler)
+		adminGroup.GET("/campaigns", hs.GetCampaignsHandler)
+		adminGroup.GET("/campaigns/:id", hs.GetCampaignHandler)
+		adminGroup.PUT("/campaigns/:id", hs.UpdateCampaignHandler)
+		adminGroup.DELETE("/campaigns/:id", hs.DeleteCampaignHandler)
+		adminGroup.GET("/orders/dead-letter", hs.GetDeadLetterOrdersHandler)
+		adminGroup.POST("/orders/:id/retry", hs.RetryOrderHandler)
+	}
+
+	internalGroup := router.Group("/internal")
+	internalGroup.Use(mws.SignatureMiddleware(cfg.AccrualCallbackSecret, cfg.AccrualCallbackMaxSkew))
+	{
+		internalGroup.POST("/accrual/callback", hs.AccrualCallbackHandler)
+	}
+
 	return &APIApp{
 		Config: cfg,
-		DB:     dbPool,
 		Router: router,
-	}, nil
+		health: checker,
+		Server: &http.Server{
+			Addr:    cfg.ServerAddress,
+			Handler: router,
+		},
+	}
 }
 
 func (a *APIApp) Run() error {
-	a.Server = &http.Server{
-		Addr:    a.Config.ServerAddress,
-		Handler: a.Router,
-	}
-
 	if err := a.Server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("server error: %w", err)
 	}
 	return nil
 }
 
+// Shutdown fails readiness first and keeps serving for
+// Config.ShutdownDrainDelay, so readiness probes see the process draining and
+// take it out of rotation, then drains open connections.
 func (a *APIApp) Shutdown(ctx context.Context) error {
+	a.health.SetDraining()
+	waitDrain(ctx, a.Config.ShutdownDrainDelay)
+
 	if err := a.Server.Shutdown(ctx); err != nil {
 		return fmt.Errorf("server shutdown error: %w", err)
 	}
-	a.DB.Close()
 	return nil
 }
+
+// waitDrain waits for delay unless ctx is done first.
+func waitDrain(ctx context.Context, delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,237 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/config"
+	"github.com/etoneja/go-gophermart/internal/db"
+	"github.com/etoneja/go-gophermart/internal/health"
+	"github.com/etoneja/go-gophermart/internal/jobs"
+	"github.com/etoneja/go-gophermart/internal/metrics"
+	"github.com/etoneja/go-gophermart/internal/processor"
+	"github.com/etoneja/go-gophermart/internal/service"
+	"github.com/etoneja/go-gophermart/internal/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+const shutdownTimeout = 30 * time.Second
+
+// App is the composition root shared by all run modes. It wires the database
+// and the service once and starts the API, the processors or both depending
+// on Config.Mode.
+type App struct {
+	Config *config.Config
+	DB     *pgxpool.Pool
+
+	api            *APIApp
+	metricsServer  *http.Server
+	health         *health.Checker
+	shutdownTraces func(context.Context) error
+	jobsWorker     *jobs.Worker
+	orderProcessor *processor.OrderProcessor
+	tierProcessor  *processor.TierProcessor
+	logger         zerolog.Logger
+}
+
+func New(ctx context.Context, args []string, logger zerolog.Logger) (*App, error) {
+	cfg, err := config.LoadConfig(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if cfg.Debug {
+		gin.SetMode(gin.DebugMode)
+	} else {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// Tracing goes first, the global provider is picked up by the DB pool
+	// and the accrual clients when they are built.
+	shutdownTraces, err := tracing.Setup(ctx, tracing.Options{
+		Exporter:    cfg.TracingExporter,
+		Endpoint:    cfg.TracingEndpoint,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up tracing: %w", err)
+	}
+
+	dbPool, err := db.NewDB(ctx, cfg.DatabaseURL)
+	if err != nil {
+		if err := shutdownTraces(ctx); err != nil {
+			logger.Error().Err(err).Msg("Error flushing traces")
+		}
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	logger.Info().Msg("Successfully connected to database")
+
+	migrator := db.NewMigrator(dbPool, logger)
+	if err := migrator.Migrate(ctx); err != nil {
+		dbPool.Close()
+		if err := shutdownTraces(ctx); err != nil {
+			logger.Error().Err(err).Msg("Error flushing traces")
+		}
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	svc := service.NewService(cfg, dbPool, logger)
+
+	registry := metrics.NewRegistry()
+	registry.MustRegister(
+		metrics.NewDBPoolCollector(dbPool),
+		metrics.NewBacklogCollector(func(ctx context.Context) (map[string]int64, error) {
+			backlog, err := svc.GetOrdersBacklog(ctx)
+			if err != nil {
+				return nil, err
+			}
+			counts := make(map[string]int64, len(backlog))
+			for status, n := range backlog {
+				counts[string(status)] = n
+			}
+			return counts, nil
+		}, logger),
+	)
+
+	a := &App{
+		Config:         cfg,
+		DB:             dbPool,
+		shutdownTraces: shutdownTraces,
+		logger:         logger.With().Str("mode", cfg.Mode).Logger(),
+	}
+
+	if cfg.RunsWorkers() {
+		a.jobsWorker = jobs.NewWorker(jobs.NewQueue(dbPool),
+			logger.With().Str("component", "jobs").Logger(),
+			jobs.WithConcurrency(cfg.JobsConcurrency),
+			jobs.WithPollInterval(cfg.JobsPollInterval))
+		a.orderProcessor = processor.NewOrderProcessor(cfg, svc, a.jobsWorker,
+			logger.With().Str("component", "processor").Logger())
+		a.tierProcessor = processor.NewTierProcessor(cfg, svc, a.jobsWorker,
+			logger.With().Str("component", "tier_processor").Logger())
+	}
+
+	checks := []health.Check{
+		{Name: "db", Critical: true, Run: dbPool.Ping},
+		{Name: "migrations", Critical: true, Run: migrator.CheckApplied},
+		{Name: "accrual", Run: svc.PingAccrualSystem},
+	}
+	if a.orderProcessor != nil {
+		checks = append(checks, health.Check{Name: "processor", Critical: true, Run: a.orderProcessor.CheckHeartbeat})
+	}
+	a.health = health.NewChecker(checks...)
+
+	if cfg.RunsAPI() {
+		a.api = NewAPIApp(cfg, svc, a.health, logger.With().Str("component", "api").Logger())
+	}
+	// Metrics are only served on their own listener, so they aren't exposed
+	// with the public API. The probes are served there too, a worker-only
+	// process has no other listener.
+	if cfg.MetricsAddress != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler(registry))
+		mux.Handle("/healthz", a.health.LivenessHandler())
+		mux.Handle("/readyz", a.health.ReadinessHandler())
+
+		a.metricsServer = &http.Server{
+			Addr:              cfg.MetricsAddress,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+	}
+
+	return a, nil
+}
+
+// Run starts the configured components and blocks until ctx is cancelled or
+// the HTTP server fails, then shuts everything down.
+func (a *App) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if a.jobsWorker != nil {
+		go a.jobsWorker.Run(ctx)
+		go a.orderProcessor.Run(ctx)
+		go a.tierProcessor.Run(ctx)
+	}
+
+	serverErrChan := make(chan error, 1)
+	if a.api != nil {
+		go func() {
+			serverErrChan <- a.api.Run()
+		}()
+	}
+	if a.metricsServer != nil {
+		go func() {
+			a.logger.Info().Str("address", a.metricsServer.Addr).Msg("Serving metrics")
+			if err := a.metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serverErrChan <- fmt.Errorf("metrics server failed: %w", err)
+			}
+		}()
+	}
+
+	a.logger.Info().Msg("Application started")
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		a.logger.Info().Msg("Received shutdown signal, initiating graceful shutdown...")
+	case runErr = <-serverErrChan:
+		a.logger.Error().Err(runErr).Msg("Server error, initiating shutdown...")
+	}
+	cancel()
+
+	a.shutdown()
+
+	return runErr
+}
+
+func (a *App) shutdown() {
+	if a.api != nil {
+		a.logger.Info().Msg("Shutting down server...")
+
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer shutdownCancel()
+
+		if err := a.api.Shutdown(shutdownCtx); err != nil {
+			a.logger.Error().Err(err).Msg("Error during server shutdown")
+		}
+	}
+
+	if a.metricsServer != nil {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer shutdownCancel()
+
+		// The API shutdown has already waited out the drain delay, a
+		// worker-only process serves its probes here and waits for them.
+		if a.api == nil {
+			a.health.SetDraining()
+			waitDrain(shutdownCtx, a.Config.ShutdownDrainDelay)
+		}
+
+		if err := a.metricsServer.Shutdown(shutdownCtx); err != nil {
+			a.logger.Error().Err(err).Msg("Error during metrics server shutdown")
+		}
+	}
+
+	if a.jobsWorker != nil {
+		a.orderProcessor.Stop()
+		a.tierProcessor.Stop()
+		a.jobsWorker.Stop()
+	}
+
+	a.DB.Close()
+
+	tracesCtx, tracesCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer tracesCancel()
+	if err := a.shutdownTraces(tracesCtx); err != nil {
+		a.logger.Error().Err(err).Msg("Error flushing traces")
+	}
+
+	a.logger.Info().Msg("Application stopped gracefully")
+}
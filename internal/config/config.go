@@ -1,33 +1,267 @@
 package config
 
 import (
+	"cmp"
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/etoneja/go-gophermart/internal/accrualclient"
+	"github.com/etoneja/go-gophermart/internal/models"
+	"github.com/etoneja/go-gophermart/internal/tracing"
+)
+
+const (
+	ModeServe  = "serve"
+	ModeWorker = "worker"
+	ModeAll    = "all"
 )
 
 type Config struct {
-	Debug                bool
-	ServerAddress        string
-	DatabaseURL          string
-	JWTSecret            string
-	AccrualSystemAddress string
-	WorkerPoolSize       int
-	WorkerInterval       time.Duration
+	Mode                   string
+	Debug                  bool
+	ServerAddress          string
+	MetricsAddress         string
+	ShutdownDrainDelay     time.Duration
+	TracingExporter        string
+	TracingEndpoint        string
+	TracingSampleRatio     float64
+	DatabaseURL            string
+	JWTSecret              string
+	AdminToken             string
+	AccrualCallbackSecret  string
+	AccrualCallbackMaxSkew time.Duration
+	AccrualSystemAddress   string
+	AccrualRateLimit       int
+	AccrualSharedLimit     bool
+	AccrualTLS             *tls.Config
+	AccrualSigningSecret   string
+	AccrualPartnerAddress  string
+	AccrualPartnerToken    string
+	AccrualPartnerTLS      *tls.Config
+	AccrualPartnerSecret   string
+	AccrualRoutes          []accrualclient.Route
+	AccrualMaxRetries      int
+	BreakerThreshold       int
+	BreakerCooldown        time.Duration
+	WorkerPoolSize         int
+	WorkerInterval         time.Duration
+	SyncBatchSize          int
+	SyncLease              time.Duration
+	SyncMinConcurrency     int
+	SyncMaxConcurrency     int
+	SyncLatencyTarget      time.Duration
+	SyncLaneWeights        map[models.SyncLane]int
+	SyncStaleAfter         time.Duration
+	JobsConcurrency        int
+	JobsPollInterval       time.Duration
+	SyncMaxAttempts        int
+	SyncBackoffBase        time.Duration
+	SyncBackoffMax         time.Duration
+	SyncRecheckDelay       time.Duration
+	UnregisteredRecheck    time.Duration
+	UnregisteredTTL        time.Duration
+	TierRecalcInterval     time.Duration
+	ReferralReward         int64
+	ReferralMonthlyLimit   int
+	TransferDailyLimit     int64
+}
+
+// parseAccrualRoutes parses "prefix=provider" pairs separated by commas.
+func parseAccrualRoutes(s string) ([]accrualclient.Route, error) {
+	var routes []accrualclient.Route
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		prefix, provider, ok := strings.Cut(pair, "=")
+		if !ok || prefix == "" || provider == "" {
+			return nil, fmt.Errorf("invalid route %q, want prefix=provider", pair)
+		}
+		switch provider {
+		case accrualclient.DefaultProvider, accrualclient.PartnerProvider:
+		default:
+			return nil, fmt.Errorf("unknown accrual provider %q", provider)
+		}
+
+		routes = append(routes, accrualclient.Route{Prefix: prefix, Provider: provider})
+	}
+	return routes, nil
+}
+
+// parseSyncLaneWeights parses "lane=weight" pairs separated by commas, every
+// lane needs a positive weight.
+func parseSyncLaneWeights(s string) (map[models.SyncLane]int, error) {
+	weights := make(map[models.SyncLane]int)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		lane, weightStr, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid lane weight %q, want lane=weight", pair)
+		}
+		if !slices.Contains(models.SyncLanes, models.SyncLane(lane)) {
+			return nil, fmt.Errorf("unknown sync lane %q", lane)
+		}
+		weight, err := strconv.Atoi(weightStr)
+		if err != nil || weight <= 0 {
+			return nil, fmt.Errorf("invalid weight %q of sync lane %s, want a positive integer", weightStr, lane)
+		}
+
+		weights[models.SyncLane(lane)] = weight
+	}
+
+	for _, lane := range models.SyncLanes {
+		if _, ok := weights[lane]; !ok {
+			return nil, fmt.Errorf("missing weight of sync lane %s", lane)
+		}
+	}
+	return weights, nil
+}
+
+// RunsAPI reports whether the process serves the HTTP API.
+func (c *Config) RunsAPI() bool {
+	return c.Mode == ModeServe || c.Mode == ModeAll
+}
+
+// RunsWorkers reports whether the process runs the background processors.
+func (c *Config) RunsWorkers() bool {
+	return c.Mode == ModeWorker || c.Mode == ModeAll
 }
 
-func LoadConfig() (*Config, error) {
+// LoadConfig parses command line args without the program name. The mode may
+// be given as a leading subcommand, e.g. "gophermart worker -d ...".
+func LoadConfig(args []string) (*Config, error) {
 	cfg := &Config{}
 
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cfg.Mode = args[0]
+		args = args[1:]
+	}
+
+	var (
+		accrualRoutes  string
+		laneWeights    string
+		accrualTLSCert string
+		accrualTLSKey  string
+		accrualTLSCA   string
+		partnerTLSCert string
+		partnerTLSKey  string
+		partnerTLSCA   string
+	)
+
+	flag.StringVar(&cfg.Mode, "mode", cmp.Or(cfg.Mode, ModeAll), "Components to run: serve, worker or all")
 	flag.BoolVar(&cfg.Debug, "debug", false, "Enable debug mode")
 	flag.StringVar(&cfg.ServerAddress, "a", ":8080", "Server address to listen on")
+	flag.StringVar(&cfg.MetricsAddress, "metrics-address", ":9090", "Address to serve /metrics, /healthz and /readyz on, empty to disable metrics")
+	flag.DurationVar(&cfg.ShutdownDrainDelay, "shutdown-drain-delay", 10*time.Second, "Time /readyz reports draining before listeners close on shutdown, keep it above the readiness probe period")
+	flag.StringVar(&cfg.TracingExporter, "tracing-exporter", tracing.ExporterNone, "Trace exporter: none, stdout (spans are written to stderr) or otlp")
+	flag.StringVar(&cfg.TracingEndpoint, "tracing-endpoint", "", "OTLP/HTTP collector URL, OTEL_EXPORTER_OTLP_* variables apply when empty")
+	flag.Float64Var(&cfg.TracingSampleRatio, "tracing-sample-ratio", 1, "Share of new traces recorded")
 	flag.StringVar(&cfg.DatabaseURL, "d", "", "Database connection URL")
 	flag.StringVar(&cfg.JWTSecret, "jwt-secret", "default-secret", "JWT secret key")
+	flag.StringVar(&cfg.AdminToken, "admin-token", "", "Admin API token, admin API is disabled when empty")
+	flag.StringVar(&cfg.AccrualCallbackSecret, "accrual-callback-secret", "", "HMAC secret of accrual callbacks, callbacks are disabled when empty")
+	flag.DurationVar(&cfg.AccrualCallbackMaxSkew, "accrual-callback-max-skew", 5*time.Minute, "Max age of a signed accrual callback")
 	flag.StringVar(&cfg.AccrualSystemAddress, "r", "", "Accrual System API base URL")
-	flag.IntVar(&cfg.WorkerPoolSize, "worker-pool-size", 5, "Worker pool size")
-	flag.DurationVar(&cfg.WorkerInterval, "worker-interval", 5*time.Second, "Worker processing interval")
-	flag.Parse()
+	flag.IntVar(&cfg.AccrualRateLimit, "accrual-rate-limit", 0, "Accrual System requests per minute, 0 until learned from a 429")
+	flag.BoolVar(&cfg.AccrualSharedLimit, "accrual-shared-limit", false, "Share the accrual rate limit between replicas through the database")
+	flag.StringVar(&accrualTLSCert, "accrual-tls-cert", "", "Client certificate for mutual TLS with the accrual system")
+	flag.StringVar(&accrualTLSKey, "accrual-tls-key", "", "Client certificate key for mutual TLS with the accrual system")
+	flag.StringVar(&accrualTLSCA, "accrual-tls-ca", "", "CA bundle to verify the accrual system certificate")
+	flag.StringVar(&cfg.AccrualSigningSecret, "accrual-signing-secret", "", "HMAC secret to sign accrual requests with, signing is disabled when empty")
+	flag.StringVar(&cfg.AccrualPartnerAddress, "accrual-partner-address", "", "Partner calculation service base URL")
+	flag.StringVar(&cfg.AccrualPartnerToken, "accrual-partner-token", "", "Partner calculation service API token")
+	flag.StringVar(&partnerTLSCert, "accrual-partner-tls-cert", "", "Client certificate for mutual TLS with the partner calculation service")
+	flag.StringVar(&partnerTLSKey, "accrual-partner-tls-key", "", "Client certificate key for mutual TLS with the partner calculation service")
+	flag.StringVar(&partnerTLSCA, "accrual-partner-tls-ca", "", "CA bundle to verify the partner calculation service certificate")
+	flag.StringVar(&cfg.AccrualPartnerSecret, "accrual-partner-signing-secret", "", "HMAC secret to sign partner calculation service requests with, signing is disabled when empty")
+	flag.StringVar(&accrualRoutes, "accrual-routes", "", "Accrual provider routing by order number prefix, e.g. 4=partner,42=default")
+	flag.IntVar(&cfg.AccrualMaxRetries, "accrual-max-retries", 2, "Retries of an accrual request failing with a network error or 5xx")
+	flag.IntVar(&cfg.BreakerThreshold, "accrual-breaker-threshold", 5, "Consecutive accrual failures that open the circuit breaker, 0 disables it")
+	flag.DurationVar(&cfg.BreakerCooldown, "accrual-breaker-cooldown", 30*time.Second, "Time the accrual circuit breaker stays open before a probe")
+	flag.IntVar(&cfg.WorkerPoolSize, "worker-pool-size", 5, "Initial number of orders synced concurrently, adapted between sync-min-concurrency and sync-max-concurrency")
+	flag.DurationVar(&cfg.WorkerInterval, "worker-interval", 5*time.Second, "Interval of the order sweep scheduling the orders sync job")
+	flag.IntVar(&cfg.SyncBatchSize, "sync-batch-size", 50, "Max orders claimed into the sync queue per orders sync job run")
+	flag.DurationVar(&cfg.SyncLease, "sync-lease", 2*time.Minute, "How long a worker holds claimed orders before others may take them over")
+	flag.IntVar(&cfg.SyncMinConcurrency, "sync-min-concurrency", 1, "Min number of orders synced concurrently")
+	flag.IntVar(&cfg.SyncMaxConcurrency, "sync-max-concurrency", 20, "Max number of orders synced concurrently")
+	flag.DurationVar(&cfg.SyncLatencyTarget, "sync-latency-target", 2*time.Second, "Latency of a batch sync call above which concurrency is reduced")
+	flag.StringVar(&laneWeights, "sync-lane-weights", "fresh=6,recheck=3,stale=1", "Share of order syncs per sync lane: fresh uploads, PROCESSING rechecks and stale orders")
+	flag.DurationVar(&cfg.SyncStaleAfter, "sync-stale-after", time.Hour, "Age after which a non-terminal order moves to the stale sync lane")
+	flag.IntVar(&cfg.JobsConcurrency, "jobs-concurrency", 4, "Background jobs run concurrently by a worker process")
+	flag.DurationVar(&cfg.JobsPollInterval, "jobs-poll-interval", 5*time.Second, "Interval of polling for due background jobs between notifications")
+	flag.IntVar(&cfg.SyncMaxAttempts, "sync-max-attempts", 20, "Failed accrual syncs before an order is dead-lettered")
+	flag.DurationVar(&cfg.SyncBackoffBase, "sync-backoff-base", 5*time.Second, "Initial delay between failed accrual syncs")
+	flag.DurationVar(&cfg.SyncBackoffMax, "sync-backoff-max", time.Hour, "Max delay between failed accrual syncs")
+	flag.DurationVar(&cfg.SyncRecheckDelay, "sync-recheck-delay", 10*time.Second, "Delay before rechecking an order the accrual system hasn't finished processing")
+	flag.DurationVar(&cfg.UnregisteredRecheck, "unregistered-recheck", 30*time.Second, "Delay before rechecking an order unknown to the accrual system")
+	flag.DurationVar(&cfg.UnregisteredTTL, "unregistered-ttl", 24*time.Hour, "Age after which an order unknown to the accrual system is invalidated")
+	flag.Int64Var(&cfg.ReferralReward, "referral-reward", 10000, "Referral reward for both users, in kopecks")
+	flag.IntVar(&cfg.ReferralMonthlyLimit, "referral-monthly-limit", 10, "Max rewarded referrals per referrer in 30 days")
+	flag.Int64Var(&cfg.TransferDailyLimit, "transfer-daily-limit", 1000000, "Max points a user can transfer in 24 hours, in kopecks")
+	flag.DurationVar(&cfg.TierRecalcInterval, "tier-recalc-interval", time.Hour, "Tier recalculation interval")
+	if err := flag.CommandLine.Parse(args); err != nil {
+		return nil, err
+	}
+	if flag.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %v", flag.Args())
+	}
+
+	if envMode, exists := os.LookupEnv("RUN_MODE"); exists {
+		cfg.Mode = envMode
+	}
+	switch cfg.Mode {
+	case ModeServe, ModeWorker, ModeAll:
+	default:
+		return nil, fmt.Errorf("unknown mode %q, want serve, worker or all", cfg.Mode)
+	}
+
+	if envMetricsAddress, exists := os.LookupEnv("METRICS_ADDRESS"); exists {
+		cfg.MetricsAddress = envMetricsAddress
+	}
+	if envShutdownDrainDelay, exists := os.LookupEnv("SHUTDOWN_DRAIN_DELAY"); exists {
+		shutdownDrainDelay, err := time.ParseDuration(envShutdownDrainDelay)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SHUTDOWN_DRAIN_DELAY: %w", err)
+		}
+		cfg.ShutdownDrainDelay = shutdownDrainDelay
+	}
+	if cfg.ShutdownDrainDelay < 0 {
+		return nil, fmt.Errorf("shutdown drain delay must not be negative")
+	}
+
+	if envTracingExporter, exists := os.LookupEnv("TRACING_EXPORTER"); exists {
+		cfg.TracingExporter = envTracingExporter
+	}
+	if envTracingEndpoint, exists := os.LookupEnv("TRACING_ENDPOINT"); exists {
+		cfg.TracingEndpoint = envTracingEndpoint
+	}
+	if envTracingSampleRatio, exists := os.LookupEnv("TRACING_SAMPLE_RATIO"); exists {
+		tracingSampleRatio, err := strconv.ParseFloat(envTracingSampleRatio, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TRACING_SAMPLE_RATIO: %w", err)
+		}
+		cfg.TracingSampleRatio = tracingSampleRatio
+	}
+	switch cfg.TracingExporter {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q, want none, stdout or otlp", cfg.TracingExporter)
+	}
+	if cfg.TracingSampleRatio < 0 || cfg.TracingSampleRatio > 1 {
+		return nil, fmt.Errorf("tracing sample ratio must be between 0 and 1")
+	}
 
 	if envServerAddress, exists := os.LookupEnv("RUN_ADDRESS"); exists {
 		cfg.ServerAddress = envServerAddress
@@ -38,10 +272,121 @@ func LoadConfig() (*Config, error) {
 	if envJWTSecret, exists := os.LookupEnv("JWT_SECRET"); exists {
 		cfg.JWTSecret = envJWTSecret
 	}
+	if envAdminToken, exists := os.LookupEnv("ADMIN_TOKEN"); exists {
+		cfg.AdminToken = envAdminToken
+	}
+	if envAccrualCallbackSecret, exists := os.LookupEnv("ACCRUAL_CALLBACK_SECRET"); exists {
+		cfg.AccrualCallbackSecret = envAccrualCallbackSecret
+	}
 	if envAccrualSystemAddress, exists := os.LookupEnv("ACCRUAL_SYSTEM_ADDRESS"); exists {
 		cfg.AccrualSystemAddress = envAccrualSystemAddress
 	}
 
+	if envAccrualRateLimit, exists := os.LookupEnv("ACCRUAL_RATE_LIMIT"); exists {
+		accrualRateLimit, err := strconv.Atoi(envAccrualRateLimit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ACCRUAL_RATE_LIMIT: %w", err)
+		}
+		cfg.AccrualRateLimit = accrualRateLimit
+	}
+	if envAccrualSharedLimit, exists := os.LookupEnv("ACCRUAL_SHARED_LIMIT"); exists {
+		accrualSharedLimit, err := strconv.ParseBool(envAccrualSharedLimit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ACCRUAL_SHARED_LIMIT: %w", err)
+		}
+		cfg.AccrualSharedLimit = accrualSharedLimit
+	}
+
+	if envAccrualTLSCert, exists := os.LookupEnv("ACCRUAL_TLS_CERT"); exists {
+		accrualTLSCert = envAccrualTLSCert
+	}
+	if envAccrualTLSKey, exists := os.LookupEnv("ACCRUAL_TLS_KEY"); exists {
+		accrualTLSKey = envAccrualTLSKey
+	}
+	if envAccrualTLSCA, exists := os.LookupEnv("ACCRUAL_TLS_CA"); exists {
+		accrualTLSCA = envAccrualTLSCA
+	}
+	if envAccrualSigningSecret, exists := os.LookupEnv("ACCRUAL_SIGNING_SECRET"); exists {
+		cfg.AccrualSigningSecret = envAccrualSigningSecret
+	}
+
+	accrualTLS, err := accrualclient.LoadTLSConfig(accrualTLSCert, accrualTLSKey, accrualTLSCA)
+	if err != nil {
+		return nil, fmt.Errorf("invalid accrual TLS config: %w", err)
+	}
+	cfg.AccrualTLS = accrualTLS
+
+	if envAccrualPartnerAddress, exists := os.LookupEnv("ACCRUAL_PARTNER_ADDRESS"); exists {
+		cfg.AccrualPartnerAddress = envAccrualPartnerAddress
+	}
+	if envAccrualPartnerToken, exists := os.LookupEnv("ACCRUAL_PARTNER_TOKEN"); exists {
+		cfg.AccrualPartnerToken = envAccrualPartnerToken
+	}
+	if envPartnerTLSCert, exists := os.LookupEnv("ACCRUAL_PARTNER_TLS_CERT"); exists {
+		partnerTLSCert = envPartnerTLSCert
+	}
+	if envPartnerTLSKey, exists := os.LookupEnv("ACCRUAL_PARTNER_TLS_KEY"); exists {
+		partnerTLSKey = envPartnerTLSKey
+	}
+	if envPartnerTLSCA, exists := os.LookupEnv("ACCRUAL_PARTNER_TLS_CA"); exists {
+		partnerTLSCA = envPartnerTLSCA
+	}
+	if envPartnerSecret, exists := os.LookupEnv("ACCRUAL_PARTNER_SIGNING_SECRET"); exists {
+		cfg.AccrualPartnerSecret = envPartnerSecret
+	}
+
+	partnerTLS, err := accrualclient.LoadTLSConfig(partnerTLSCert, partnerTLSKey, partnerTLSCA)
+	if err != nil {
+		return nil, fmt.Errorf("invalid accrual partner TLS config: %w", err)
+	}
+	cfg.AccrualPartnerTLS = partnerTLS
+	if envAccrualRoutes, exists := os.LookupEnv("ACCRUAL_ROUTES"); exists {
+		accrualRoutes = envAccrualRoutes
+	}
+
+	routes, err := parseAccrualRoutes(accrualRoutes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid accrual routes: %w", err)
+	}
+	cfg.AccrualRoutes = routes
+
+	for _, route := range cfg.AccrualRoutes {
+		if route.Provider == accrualclient.PartnerProvider && cfg.AccrualPartnerAddress == "" {
+			return nil, fmt.Errorf("partner address is required to route orders to the partner provider")
+		}
+	}
+
+	if envLaneWeights, exists := os.LookupEnv("SYNC_LANE_WEIGHTS"); exists {
+		laneWeights = envLaneWeights
+	}
+
+	cfg.SyncLaneWeights, err = parseSyncLaneWeights(laneWeights)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sync lane weights: %w", err)
+	}
+
+	if cfg.WorkerInterval <= 0 {
+		return nil, fmt.Errorf("worker interval must be positive")
+	}
+	if cfg.TierRecalcInterval <= 0 {
+		return nil, fmt.Errorf("tier recalculation interval must be positive")
+	}
+	if cfg.SyncMaxAttempts <= 0 {
+		return nil, fmt.Errorf("sync max attempts must be positive")
+	}
+	if cfg.SyncBackoffBase <= 0 || cfg.SyncBackoffMax <= 0 {
+		return nil, fmt.Errorf("sync backoff base and max must be positive")
+	}
+	if cfg.SyncBackoffBase > cfg.SyncBackoffMax {
+		return nil, fmt.Errorf("sync backoff base must not exceed its max")
+	}
+	if cfg.SyncRecheckDelay <= 0 {
+		return nil, fmt.Errorf("sync recheck delay must be positive")
+	}
+	if cfg.AccrualMaxRetries < 0 {
+		return nil, fmt.Errorf("accrual max retries must not be negative")
+	}
+
 	if cfg.DatabaseURL == "" {
 		return nil, fmt.Errorf("database URL is required")
 	}
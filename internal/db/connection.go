@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/rs/zerolog/log"
 )
 
 func NewDB(ctx context.Context, connString string) (*pgxpool.Pool, error) {
@@ -20,6 +19,7 @@ func NewDB(ctx context.Context, connString string) (*pgxpool.Pool, error) {
 	config.HealthCheckPeriod = 1 * time.Minute
 	config.MaxConnLifetime = 2 * time.Hour
 	config.MaxConnIdleTime = 30 * time.Minute
+	config.ConnConfig.Tracer = queryTracer{}
 
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
@@ -30,6 +30,5 @@ func NewDB(ctx context.Context, connString string) (*pgxpool.Pool, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	log.Info().Msg("Successfully connected to database")
 	return pool, nil
 }
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx__transactions__user_id__program_code;
+ALTER TABLE transactions DROP COLUMN IF EXISTS program_code;
+ALTER TABLE orders DROP COLUMN IF EXISTS program_code;
+
+ALTER TABLE users ADD COLUMN balance BIGINT NOT NULL DEFAULT 0;
+UPDATE users AS u
+SET balance = a.balance
+FROM accounts AS a
+WHERE a.user_id = u.uuid AND a.program_code = 'gophermart';
+
+DROP TABLE IF EXISTS accounts;
+DROP TABLE IF EXISTS programs;
@@ -0,0 +1,51 @@
+CREATE TABLE programs (
+    code VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    accrual_rate INT NOT NULL CHECK (accrual_rate >= 0),
+    conversion_rate INT NOT NULL CHECK (conversion_rate > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO programs (code, name, accrual_rate, conversion_rate)
+VALUES ('gophermart', 'Gophermart points', 10000, 10000);
+
+CREATE TABLE accounts (
+    user_id UUID NOT NULL,
+    program_code VARCHAR(50) NOT NULL,
+    balance BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, program_code),
+    CONSTRAINT fk__accounts__user
+        FOREIGN KEY (user_id)
+        REFERENCES users(uuid)
+        ON DELETE RESTRICT
+        ON UPDATE RESTRICT,
+    CONSTRAINT fk__accounts__program
+        FOREIGN KEY (program_code)
+        REFERENCES programs(code)
+        ON DELETE RESTRICT
+        ON UPDATE RESTRICT
+);
+
+INSERT INTO accounts (user_id, program_code, balance, created_at)
+SELECT uuid, 'gophermart', balance, created_at FROM users;
+
+ALTER TABLE users DROP COLUMN balance;
+
+ALTER TABLE orders
+    ADD COLUMN program_code VARCHAR(50) NOT NULL DEFAULT 'gophermart',
+    ADD CONSTRAINT fk__orders__program
+        FOREIGN KEY (program_code)
+        REFERENCES programs(code)
+        ON DELETE RESTRICT
+        ON UPDATE RESTRICT;
+
+ALTER TABLE transactions
+    ADD COLUMN program_code VARCHAR(50) NOT NULL DEFAULT 'gophermart',
+    ADD CONSTRAINT fk__transactions__program
+        FOREIGN KEY (program_code)
+        REFERENCES programs(code)
+        ON DELETE RESTRICT
+        ON UPDATE RESTRICT;
+
+CREATE INDEX idx__transactions__user_id__program_code ON transactions(user_id, program_code);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx__transactions__type__created_at;
+DROP TABLE IF EXISTS user_tiers;
@@ -0,0 +1,13 @@
+CREATE TABLE user_tiers (
+    user_id UUID PRIMARY KEY,
+    tier VARCHAR(20) NOT NULL,
+    accrued BIGINT NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk__user_tiers__user
+        FOREIGN KEY (user_id)
+        REFERENCES users(uuid)
+        ON DELETE RESTRICT
+        ON UPDATE RESTRICT
+);
+
+CREATE INDEX idx__transactions__type__created_at ON transactions(type, created_at);
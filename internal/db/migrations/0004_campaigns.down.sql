@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx__transactions__campaign_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS campaign_id;
+DROP TABLE IF EXISTS campaigns;
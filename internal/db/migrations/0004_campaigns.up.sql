@@ -0,0 +1,29 @@
+CREATE TABLE campaigns (
+    uuid UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    program_code VARCHAR(50) NOT NULL,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NULL CHECK (ends_at > starts_at),
+    active BOOLEAN NOT NULL,
+    eligibility JSONB NOT NULL,
+    bonus JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk__campaigns__program
+        FOREIGN KEY (program_code)
+        REFERENCES programs(code)
+        ON DELETE RESTRICT
+        ON UPDATE RESTRICT
+);
+
+CREATE INDEX idx__campaigns__program_code__starts_at ON campaigns(program_code, starts_at);
+
+ALTER TABLE transactions
+    ADD COLUMN campaign_id UUID NULL,
+    ADD CONSTRAINT fk__transactions__campaign
+        FOREIGN KEY (campaign_id)
+        REFERENCES campaigns(uuid)
+        ON DELETE RESTRICT
+        ON UPDATE RESTRICT;
+
+CREATE INDEX idx__transactions__campaign_id ON transactions(campaign_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS referrals;
+DROP INDEX IF EXISTS uniq__users__referral_code;
+ALTER TABLE users DROP COLUMN IF EXISTS referral_code;
@@ -0,0 +1,24 @@
+ALTER TABLE users ADD COLUMN referral_code VARCHAR(16) NULL;
+UPDATE users SET referral_code = UPPER(SUBSTRING(REPLACE(uuid::TEXT, '-', '') FROM 1 FOR 10));
+ALTER TABLE users ALTER COLUMN referral_code SET NOT NULL;
+CREATE UNIQUE INDEX uniq__users__referral_code ON users(referral_code);
+
+CREATE TABLE referrals (
+    referee_id UUID PRIMARY KEY,
+    referrer_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    rewarded_at TIMESTAMP NULL,
+    CONSTRAINT fk__referrals__referee
+        FOREIGN KEY (referee_id)
+        REFERENCES users(uuid)
+        ON DELETE RESTRICT
+        ON UPDATE RESTRICT,
+    CONSTRAINT fk__referrals__referrer
+        FOREIGN KEY (referrer_id)
+        REFERENCES users(uuid)
+        ON DELETE RESTRICT
+        ON UPDATE RESTRICT
+);
+
+CREATE INDEX idx__referrals__referrer_id__status ON referrals(referrer_id, status);
@@ -0,0 +1,20 @@
+-- Transfers and balance conversions are the only ledger rows without an
+-- order. Their balance effect is reverted before the rows go, so accounts
+-- keep matching their ledger. The transfer and conversion history is lost.
+UPDATE accounts a
+SET balance = a.balance - t.delta
+FROM (
+    SELECT
+        user_id,
+        program_code,
+        SUM(CASE WHEN type IN ('transfer_out', 'conversion_out') THEN -amount ELSE amount END) AS delta
+    FROM transactions
+    WHERE order_id IS NULL
+    GROUP BY user_id, program_code
+) t
+WHERE a.user_id = t.user_id AND a.program_code = t.program_code;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS transfer_id;
+DELETE FROM transactions WHERE order_id IS NULL;
+ALTER TABLE transactions ALTER COLUMN order_id SET NOT NULL;
+DROP TABLE IF EXISTS transfers;
@@ -0,0 +1,37 @@
+CREATE TABLE transfers (
+    uuid UUID PRIMARY KEY,
+    sender_id UUID NOT NULL,
+    recipient_id UUID NOT NULL,
+    program_code VARCHAR(50) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    idempotency_key VARCHAR(255) NULL,
+    created_at TIMESTAMP NOT NULL,
+    CHECK (sender_id != recipient_id),
+    CONSTRAINT fk__transfers__sender
+        FOREIGN KEY (sender_id)
+        REFERENCES users(uuid)
+        ON DELETE RESTRICT
+        ON UPDATE RESTRICT,
+    CONSTRAINT fk__transfers__recipient
+        FOREIGN KEY (recipient_id)
+        REFERENCES users(uuid)
+        ON DELETE RESTRICT
+        ON UPDATE RESTRICT,
+    CONSTRAINT fk__transfers__program
+        FOREIGN KEY (program_code)
+        REFERENCES programs(code)
+        ON DELETE RESTRICT
+        ON UPDATE RESTRICT
+);
+
+CREATE UNIQUE INDEX uniq__transfers__sender_id__idempotency_key ON transfers(sender_id, idempotency_key);
+CREATE INDEX idx__transfers__sender_id__created_at ON transfers(sender_id, created_at);
+
+ALTER TABLE transactions
+    ALTER COLUMN order_id DROP NOT NULL,
+    ADD COLUMN transfer_id UUID NULL,
+    ADD CONSTRAINT fk__transactions__transfer
+        FOREIGN KEY (transfer_id)
+        REFERENCES transfers(uuid)
+        ON DELETE RESTRICT
+        ON UPDATE RESTRICT;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx__orders__dead_lettered_at;
+DROP INDEX IF EXISTS idx__orders__next_sync_at;
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS dead_lettered_at,
+    DROP COLUMN IF EXISTS last_sync_error,
+    DROP COLUMN IF EXISTS next_sync_at,
+    DROP COLUMN IF EXISTS sync_attempts;
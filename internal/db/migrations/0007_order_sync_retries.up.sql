@@ -0,0 +1,8 @@
+ALTER TABLE orders
+    ADD COLUMN sync_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN next_sync_at TIMESTAMP NULL,
+    ADD COLUMN last_sync_error TEXT NULL,
+    ADD COLUMN dead_lettered_at TIMESTAMP NULL;
+
+CREATE INDEX idx__orders__next_sync_at ON orders(next_sync_at) WHERE dead_lettered_at IS NULL;
+CREATE INDEX idx__orders__dead_lettered_at ON orders(dead_lettered_at) WHERE dead_lettered_at IS NOT NULL;
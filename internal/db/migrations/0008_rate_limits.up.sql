@@ -0,0 +1,7 @@
+CREATE TABLE rate_limits (
+    name VARCHAR(50) PRIMARY KEY,
+    rate DOUBLE PRECISION NOT NULL CHECK (rate >= 0),
+    tokens DOUBLE PRECISION NOT NULL,
+    blocked_until TIMESTAMPTZ NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,3 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS claimed_until,
+    DROP COLUMN IF EXISTS claimed_by;
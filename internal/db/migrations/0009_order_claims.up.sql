@@ -0,0 +1,3 @@
+ALTER TABLE orders
+    ADD COLUMN claimed_by TEXT NULL,
+    ADD COLUMN claimed_until TIMESTAMP NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx__jobs__kind__run_at;
+DROP INDEX IF EXISTS uniq__jobs__unique_key;
+DROP TABLE IF EXISTS jobs;
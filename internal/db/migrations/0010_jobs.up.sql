@@ -0,0 +1,18 @@
+CREATE TABLE jobs (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    unique_key TEXT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL CHECK (max_attempts > 0),
+    run_at TIMESTAMP NOT NULL,
+    last_error TEXT NULL,
+    locked_by TEXT NULL,
+    locked_until TIMESTAMP NULL,
+    dead_lettered_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX uniq__jobs__unique_key ON jobs(unique_key) WHERE dead_lettered_at IS NULL;
+CREATE INDEX idx__jobs__kind__run_at ON jobs(kind, run_at) WHERE dead_lettered_at IS NULL;
@@ -71,10 +71,9 @@ func (m *Migrator) Migrate(ctx context.Context) error {
 			continue
 		}
 
-		versionStr := strings.Split(file.Name(), "_")[0]
-		version, err := strconv.Atoi(versionStr)
+		version, err := migrationVersion(file.Name())
 		if err != nil {
-			return fmt.Errorf("invalid migration file name: %s", file.Name())
+			return err
 		}
 
 		if applied[version] {
@@ -122,3 +121,39 @@ func (m *Migrator) Migrate(ctx context.Context) error {
 
 	return nil
 }
+
+// CheckApplied returns an error unless the newest embedded migration has
+// been applied to the database.
+func (m *Migrator) CheckApplied(ctx context.Context) error {
+	files, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	var latest int
+	for _, file := range files {
+		version, err := migrationVersion(file.Name())
+		if err != nil {
+			return err
+		}
+		latest = max(latest, version)
+	}
+
+	var applied int
+	err = m.db.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM migrations`).Scan(&applied)
+	if err != nil {
+		return fmt.Errorf("failed to query applied migrations: %w", err)
+	}
+	if applied < latest {
+		return fmt.Errorf("database is at migration %d, latest is %d", applied, latest)
+	}
+	return nil
+}
+
+func migrationVersion(fileName string) (int, error) {
+	version, err := strconv.Atoi(strings.Split(fileName, "_")[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid migration file name: %s", fileName)
+	}
+	return version, nil
+}
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"context"
+	"strings"
+
+	"github.com/etoneja/go-gophermart/internal/tracing"
+	"github.com/jackc/pgx/v5"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = tracing.Tracer("db")
+
+// queryTracer starts a span for every query run through the pool, so each
+// repository call shows up under the transaction span.
+type queryTracer struct{}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation := queryOperation(data.SQL)
+	ctx, _ = tracer.Start(ctx, "db."+strings.ToLower(operation),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(data.SQL),
+		))
+	return ctx
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	tracing.End(trace.SpanFromContext(ctx), data.Err)
+}
+
+// queryOperation returns the leading SQL keyword, e.g. SELECT or WITH.
+func queryOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(fields[0])
+}
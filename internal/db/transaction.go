@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 
+	"github.com/etoneja/go-gophermart/internal/tracing"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -26,7 +27,18 @@ func GetTxFromContext(ctx context.Context) pgx.Tx {
 	return nil
 }
 
-func WithTx(ctx context.Context, db *pgxpool.Pool, fn func(context.Context) error) error {
+// WithTx runs fn in a transaction traced as a db.tx span, the queries of fn
+// become its children.
+func WithTx(ctx context.Context, db *pgxpool.Pool, fn func(context.Context) error) (err error) {
+	ctx, span := tracer.Start(ctx, "db.tx")
+	defer func() { tracing.End(span, err) }()
+
+	return withTx(ctx, db, fn)
+}
+
+// withTx commits when fn succeeds, the named result makes a failed commit
+// the error of the call.
+func withTx(ctx context.Context, db *pgxpool.Pool, fn func(context.Context) error) (err error) {
 	txCtx, tx, err := BeginTx(ctx, db)
 	if err != nil {
 		return err
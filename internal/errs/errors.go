@@ -12,3 +12,15 @@ var ErrInsufficientFunds = errors.New("insufficient funds")
 var ErrNotOnlyOneRowAffected = errors.New("zero or more than one row affected")
 var ErrNoRows = errors.New("no rows")
 var ErrRateLimit = errors.New("rate limited")
+var ErrProgramNotFound = errors.New("loyalty program not found")
+var ErrCampaignInUse = errors.New("campaign is referenced by transactions")
+var ErrInvalidReferralCode = errors.New("invalid referral code")
+var ErrReferralCodeTaken = errors.New("referral code already taken")
+var ErrRecipientNotFound = errors.New("recipient not found")
+var ErrSelfTransfer = errors.New("can't transfer to yourself")
+var ErrTransferLimitExceeded = errors.New("daily transfer limit exceeded")
+var ErrIdempotencyKeyReused = errors.New("idempotency key reused with different parameters")
+var ErrOrderTerminated = errors.New("order already in terminal status")
+var ErrSameProgram = errors.New("can't convert to the same program")
+var ErrConversionTooSmall = errors.New("amount too small to convert")
+var ErrConversionOutOfRange = errors.New("converted amount out of range")
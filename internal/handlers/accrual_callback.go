@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/etoneja/go-gophermart/internal/errs"
+	"github.com/etoneja/go-gophermart/internal/httperr"
+	"github.com/etoneja/go-gophermart/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) AccrualCallbackHandler(c *gin.Context) {
+	var req models.AccrualOrderResponse
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httperr.Response(c, err.Error()))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httperr.Response(c, err.Error()))
+		return
+	}
+
+	err := h.svc.ApplyAccrualUpdate(c.Request.Context(), req.ToModel())
+	if err != nil {
+		if errors.Is(err, errs.ErrNoRows) {
+			c.JSON(http.StatusNotFound, httperr.Response(c, "order not found"))
+			return
+		}
+		h.log(c).Error().Err(err).Str("orderID", req.Order).Msg("Failed to apply accrual callback")
+		c.JSON(http.StatusInternalServerError, httperr.Response(c, http.StatusText(http.StatusInternalServerError)))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/etoneja/go-gophermart/internal/errs"
+	"github.com/etoneja/go-gophermart/internal/httperr"
+	"github.com/etoneja/go-gophermart/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) GetDeadLetterOrdersHandler(c *gin.Context) {
+	orders, err := h.svc.GetDeadLetterOrders(c.Request.Context())
+	if err != nil {
+		h.log(c).Error().Err(err).Msg("Failed to get dead-lettered orders")
+		c.JSON(http.StatusInternalServerError, httperr.Response(c, http.StatusText(http.StatusInternalServerError)))
+		return
+	}
+
+	c.JSON(http.StatusOK, orders.ToAdminResponse())
+}
+
+func (h *Handlers) RetryOrderHandler(c *gin.Context) {
+	orderID := c.Param("id")
+	if _, err := utils.LuhnCheck(orderID); err != nil {
+		c.JSON(http.StatusNotFound, httperr.Response(c, "order not found"))
+		return
+	}
+
+	order, err := h.svc.RetryOrder(c.Request.Context(), orderID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNoRows) {
+			c.JSON(http.StatusNotFound, httperr.Response(c, "order not found"))
+			return
+		}
+		if errors.Is(err, errs.ErrOrderTerminated) {
+			c.JSON(http.StatusConflict, httperr.Response(c, "order already in terminal status"))
+			return
+		}
+		h.log(c).Error().Err(err).Msg("Failed to retry order")
+		c.JSON(http.StatusInternalServerError, httperr.Response(c, http.StatusText(http.StatusInternalServerError)))
+		return
+	}
+
+	c.JSON(http.StatusOK, order.ToAdminResponse())
+}
@@ -5,14 +5,16 @@ import (
 	"net/http"
 
 	"github.com/etoneja/go-gophermart/internal/errs"
+	"github.com/etoneja/go-gophermart/internal/httperr"
 	"github.com/gin-gonic/gin"
 )
 
 const AuthorizationHeader = "Authorization"
 
 type RegisterRequest struct {
-	Login    string `json:"login" binding:"required,min=3,max=25"`
-	Password string `json:"password" binding:"required,min=3"`
+	Login        string `json:"login" binding:"required,min=3,max=25"`
+	Password     string `json:"password" binding:"required,min=3"`
+	ReferralCode string `json:"referral_code" binding:"omitempty,max=16"`
 }
 
 type LoginRequest struct {
@@ -23,19 +25,23 @@ type LoginRequest struct {
 func (h *Handlers) RegisterUserHandler(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Warn().Err(err).Msg("Invalid registration payload")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log(c).Warn().Err(err).Msg("Invalid registration payload")
+		c.JSON(http.StatusBadRequest, httperr.Response(c, err.Error()))
 		return
 	}
 
-	_, token, err := h.svc.RegisterUser(c.Request.Context(), req.Login, req.Password)
+	_, token, err := h.svc.RegisterUser(c.Request.Context(), req.Login, req.Password, req.ReferralCode)
 	if err != nil {
 		if errors.Is(err, errs.ErrUserExists) {
-			c.JSON(http.StatusConflict, gin.H{"error": "login already registered"})
+			c.JSON(http.StatusConflict, httperr.Response(c, "login already registered"))
 			return
 		}
-		h.logger.Error().Err(err).Msg("Failed to register user")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": http.StatusText(http.StatusInternalServerError)})
+		if errors.Is(err, errs.ErrInvalidReferralCode) {
+			c.JSON(http.StatusBadRequest, httperr.Response(c, "invalid referral code"))
+			return
+		}
+		h.log(c).Error().Err(err).Msg("Failed to register user")
+		c.JSON(http.StatusInternalServerError, httperr.Response(c, http.StatusText(http.StatusInternalServerError)))
 		return
 	}
 
@@ -46,15 +52,15 @@ func (h *Handlers) RegisterUserHandler(c *gin.Context) {
 func (h *Handlers) LoginUserHandler(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Warn().Err(err).Msg("Invalid login payload")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.log(c).Warn().Err(err).Msg("Invalid login payload")
+		c.JSON(http.StatusBadRequest, httperr.Response(c, err.Error()))
 		return
 	}
 
 	_, token, err := h.svc.LoginUser(c.Request.Context(), req.Login, req.Password)
 	if err != nil {
-		h.logger.Warn().Err(err).Str("username", req.Login).Msg("Login failed")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		h.log(c).Warn().Err(err).Str("username", req.Login).Msg("Login failed")
+		c.JSON(http.StatusUnauthorized, httperr.Response(c, "invalid credentials"))
 		return
 	}
 
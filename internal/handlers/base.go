@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"github.com/etoneja/go-gophermart/internal/logging"
 	"github.com/etoneja/go-gophermart/internal/service"
+	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
 )
 
@@ -16,3 +18,9 @@ func NewHandlers(svc service.Servicer, logger zerolog.Logger) *Handlers {
 		logger: logger,
 	}
 }
+
+// log returns the request-scoped logger, carrying the request ID and the
+// user UUID once authenticated.
+func (h *Handlers) log(c *gin.Context) *zerolog.Logger {
+	return logging.FromContext(c.Request.Context(), &h.logger)
+}
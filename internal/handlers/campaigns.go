@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/etoneja/go-gophermart/internal/errs"
+	"github.com/etoneja/go-gophermart/internal/httperr"
+	"github.com/etoneja/go-gophermart/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *Handlers) CreateCampaignHandler(c *gin.Context) {
+	campaign, ok := h.bindCampaign(c)
+	if !ok {
+		return
+	}
+
+	err := h.svc.CreateCampaign(c.Request.Context(), campaign)
+	if err != nil {
+		if errors.Is(err, errs.ErrProgramNotFound) {
+			c.JSON(http.StatusBadRequest, httperr.Response(c, "unknown loyalty program"))
+			return
+		}
+		h.log(c).Error().Err(err).Msg("Failed to create campaign")
+		c.JSON(http.StatusInternalServerError, httperr.Response(c, http.StatusText(http.StatusInternalServerError)))
+		return
+	}
+
+	c.JSON(http.StatusCreated, campaign.ToResponse())
+}
+
+func (h *Handlers) GetCampaignsHandler(c *gin.Context) {
+	campaigns, err := h.svc.GetCampaigns(c.Request.Context())
+	if err != nil {
+		h.log(c).Error().Err(err).Msg("Failed to get campaigns")
+		c.JSON(http.StatusInternalServerError, httperr.Response(c, http.StatusText(http.StatusInternalServerError)))
+		return
+	}
+
+	c.JSON(http.StatusOK, campaigns.ToResponse())
+}
+
+func (h *Handlers) GetCampaignHandler(c *gin.Context) {
+	campaignID, ok := campaignIDParam(c)
+	if !ok {
+		return
+	}
+
+	campaign, err := h.svc.GetCampaign(c.Request.Context(), campaignID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNoRows) {
+			c.JSON(http.StatusNotFound, httperr.Response(c, "campaign not found"))
+			return
+		}
+		h.log(c).Error().Err(err).Msg("Failed to get campaign")
+		c.JSON(http.StatusInternalServerError, httperr.Response(c, http.StatusText(http.StatusInternalServerError)))
+		return
+	}
+
+	c.JSON(http.StatusOK, campaign.ToResponse())
+}
+
+func (h *Handlers) UpdateCampaignHandler(c *gin.Context) {
+	campaignID, ok := campaignIDParam(c)
+	if !ok {
+		return
+	}
+
+	campaign, ok := h.bindCampaign(c)
+	if !ok {
+		return
+	}
+	campaign.UUID = campaignID
+
+	err := h.svc.UpdateCampaign(c.Request.Context(), campaign)
+	if err != nil {
+		if errors.Is(err, errs.ErrNoRows) {
+			c.JSON(http.StatusNotFound, httperr.Response(c, "campaign not found"))
+			return
+		}
+		if errors.Is(err, errs.ErrProgramNotFound) {
+			c.JSON(http.StatusBadRequest, httperr.Response(c, "unknown loyalty program"))
+			return
+		}
+		h.log(c).Error().Err(err).Msg("Failed to update campaign")
+		c.JSON(http.StatusInternalServerError, httperr.Response(c, http.StatusText(http.StatusInternalServerError)))
+		return
+	}
+
+	c.JSON(http.StatusOK, campaign.ToResponse())
+}
+
+func (h *Handlers) DeleteCampaignHandler(c *gin.Context) {
+	campaignID, ok := campaignIDParam(c)
+	if !ok {
+		return
+	}
+
+	err := h.svc.DeleteCampaign(c.Request.Context(), campaignID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNoRows) {
+			c.JSON(http.StatusNotFound, httperr.Response(c, "campaign not found"))
+			return
+		}
+		if errors.Is(err, errs.ErrCampaignInUse) {
+			c.JSON(http.StatusConflict, httperr.Response(c, "campaign already credited bonuses, deactivate it instead"))
+			return
+		}
+		h.log(c).Error().Err(err).Msg("Failed to delete campaign")
+		c.JSON(http.StatusInternalServerError, httperr.Response(c, http.StatusText(http.StatusInternalServerError)))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handlers) bindCampaign(c *gin.Context) (*models.CampaignModel, bool) {
+	var req models.CampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httperr.Response(c, "invalid request: "+err.Error()))
+		return nil, false
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httperr.Response(c, "invalid request: "+err.Error()))
+		return nil, false
+	}
+
+	return req.ToModel(), true
+}
+
+func campaignIDParam(c *gin.Context) (string, bool) {
+	campaignID := c.Param("id")
+	if _, err := uuid.Parse(campaignID); err != nil {
+		c.JSON(http.StatusNotFound, httperr.Response(c, "campaign not found"))
+		return "", false
+	}
+	return campaignID, true
+}
@@ -1,11 +1,16 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/etoneja/go-gophermart/internal/errs"
+	"github.com/etoneja/go-gophermart/internal/logging"
 	"github.com/etoneja/go-gophermart/internal/models"
 	"github.com/etoneja/go-gophermart/internal/service/mocks"
 	"github.com/gin-gonic/gin"
@@ -29,7 +34,7 @@ func TestGetBalanceHandler(t *testing.T) {
 	}
 
 	mockSvc.EXPECT().
-		GetUserBalance(gomock.Any(), testUser.UUID).
+		GetUserBalance(gomock.Any(), testUser.UUID, models.DefaultProgramCode).
 		Return(balance, nil).
 		Times(1)
 
@@ -54,3 +59,267 @@ func TestGetBalanceHandler(t *testing.T) {
 	assert.Equal(t, expectedResponse.Current, response.Current)
 	assert.Equal(t, expectedResponse.Withdrawn, response.Withdrawn)
 }
+
+func TestCreateTransferHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		svcErr     error
+		wantStatus int
+	}{
+		{name: "success", wantStatus: http.StatusOK},
+		{name: "insufficient funds", svcErr: errs.ErrInsufficientFunds, wantStatus: http.StatusPaymentRequired},
+		{name: "unknown recipient", svcErr: errs.ErrRecipientNotFound, wantStatus: http.StatusNotFound},
+		{name: "limit exceeded", svcErr: errs.ErrTransferLimitExceeded, wantStatus: http.StatusUnprocessableEntity},
+		{name: "key reused", svcErr: errs.ErrIdempotencyKeyReused, wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockSvc := mocks.NewMockServicer(ctrl)
+			hs := NewHandlers(mockSvc, zerolog.Nop())
+
+			testUser := &models.UserModel{UUID: "fakeUUID"}
+
+			mockSvc.EXPECT().
+				CreateTransfer(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, transfer *models.TransferModel) (*models.TransferModel, error) {
+					assert.Equal(t, testUser.UUID, transfer.SenderID)
+					assert.Equal(t, "friend", transfer.RecipientLogin)
+					assert.Equal(t, int64(1050), transfer.Amount)
+					require.NotNil(t, transfer.IdempotencyKey)
+					assert.Equal(t, "key-1", *transfer.IdempotencyKey)
+					if tt.svcErr != nil {
+						return nil, tt.svcErr
+					}
+					return transfer, nil
+				}).
+				Times(1)
+
+			req, err := http.NewRequest("POST", "/", strings.NewReader(`{"login":"friend","sum":10.5}`))
+			require.NoError(t, err)
+			req.Header.Set(IdempotencyKeyHeader, "key-1")
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("user", testUser)
+			c.Request = req
+
+			hs.CreateTransferHandler(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestConvertBalanceHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		svcErr     error
+		wantStatus int
+	}{
+		{name: "success", wantStatus: http.StatusOK},
+		{name: "insufficient funds", svcErr: errs.ErrInsufficientFunds, wantStatus: http.StatusPaymentRequired},
+		{name: "unknown program", svcErr: errs.ErrProgramNotFound, wantStatus: http.StatusBadRequest},
+		{name: "same program", svcErr: errs.ErrSameProgram, wantStatus: http.StatusBadRequest},
+		{name: "too small", svcErr: errs.ErrConversionTooSmall, wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockSvc := mocks.NewMockServicer(ctrl)
+			hs := NewHandlers(mockSvc, zerolog.Nop())
+
+			testUser := &models.UserModel{UUID: "fakeUUID"}
+
+			mockSvc.EXPECT().
+				ConvertBalance(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, conversion *models.ConversionModel) error {
+					assert.Equal(t, testUser.UUID, conversion.UserID)
+					assert.Equal(t, models.DefaultProgramCode, conversion.FromProgramCode)
+					assert.Equal(t, "miles", conversion.ToProgramCode)
+					assert.Equal(t, int64(1000), conversion.Amount)
+					conversion.Converted = 250
+					return tt.svcErr
+				}).
+				Times(1)
+
+			req, err := http.NewRequest("POST", "/", strings.NewReader(`{"to":"miles","sum":10}`))
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("user", testUser)
+			c.Request = req
+
+			hs.ConvertBalanceHandler(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.svcErr == nil {
+				var response models.ConversionResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, models.Money(250), response.Converted)
+			}
+		})
+	}
+}
+
+func TestAccrualCallbackHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		callSvc    bool
+		svcErr     error
+		wantStatus int
+	}{
+		{
+			name:       "processed",
+			body:       `{"order":"12345678903","status":"PROCESSED","accrual":5.5}`,
+			callSvc:    true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unknown order",
+			body:       `{"order":"12345678903","status":"PROCESSING"}`,
+			callSvc:    true,
+			svcErr:     errs.ErrNoRows,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "invalid order number",
+			body:       `{"order":"12345678900","status":"PROCESSED"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown status",
+			body:       `{"order":"12345678903","status":"DONE"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "accrual for unprocessed order",
+			body:       `{"order":"12345678903","status":"PROCESSING","accrual":5}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockSvc := mocks.NewMockServicer(ctrl)
+			hs := NewHandlers(mockSvc, zerolog.Nop())
+
+			if tt.callSvc {
+				mockSvc.EXPECT().
+					ApplyAccrualUpdate(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, accrualOrder *models.AccrualOrderModel) error {
+						assert.Equal(t, "12345678903", accrualOrder.ID)
+						return tt.svcErr
+					}).
+					Times(1)
+			}
+
+			req, err := http.NewRequest("POST", "/", strings.NewReader(tt.body))
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			hs.AccrualCallbackHandler(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestErrorResponse_EchoesRequestID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := mocks.NewMockServicer(ctrl)
+	hs := NewHandlers(mockSvc, zerolog.Nop())
+
+	testUser := &models.UserModel{UUID: "fakeUUID"}
+	mockSvc.EXPECT().
+		GetUserBalances(gomock.Any(), testUser.UUID).
+		Return(nil, errors.New("db is down")).
+		Times(1)
+
+	req, err := http.NewRequest("GET", "/", nil)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user", testUser)
+	c.Request = req.WithContext(logging.WithRequestID(req.Context(), "req-123"))
+
+	hs.GetBalancesHandler(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var response map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "req-123", response["request_id"])
+}
+
+func TestBadRequest_EchoesRequestID(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		handler func(hs *Handlers) gin.HandlerFunc
+	}{
+		{
+			name:    "withdraw bind",
+			body:    `{"order":"12345678903"}`,
+			handler: func(hs *Handlers) gin.HandlerFunc { return hs.CreateWithdrawHandler },
+		},
+		{
+			name:    "transfer bind",
+			body:    `{"login":"friend","sum":-1}`,
+			handler: func(hs *Handlers) gin.HandlerFunc { return hs.CreateTransferHandler },
+		},
+		{
+			name:    "campaign bind",
+			body:    `{"starts_at":"2025-01-01T00:00:00Z"}`,
+			handler: func(hs *Handlers) gin.HandlerFunc { return hs.CreateCampaignHandler },
+		},
+		{
+			name:    "campaign validation",
+			body:    `{"name":"spring","starts_at":"2025-02-01T00:00:00Z","ends_at":"2025-01-01T00:00:00Z"}`,
+			handler: func(hs *Handlers) gin.HandlerFunc { return hs.CreateCampaignHandler },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			hs := NewHandlers(mocks.NewMockServicer(ctrl), zerolog.Nop())
+
+			req, err := http.NewRequest("POST", "/", strings.NewReader(tt.body))
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("user", &models.UserModel{UUID: "fakeUUID"})
+			c.Request = req.WithContext(logging.WithRequestID(req.Context(), "req-400"))
+
+			tt.handler(hs)(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response map[string]string
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "req-400", response["request_id"])
+			assert.Contains(t, response["error"], "invalid request")
+		})
+	}
+}
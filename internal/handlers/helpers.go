@@ -20,3 +20,9 @@ func GetCurrentUser(c *gin.Context) (*models.UserModel, error) {
 
 	return usr, nil
 }
+
+// GetProgramCode returns the loyalty program requested via the "program"
+// query parameter, falling back to the default program.
+func GetProgramCode(c *gin.Context) string {
+	return c.DefaultQuery("program", models.DefaultProgramCode)
+}
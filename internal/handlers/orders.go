@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/etoneja/go-gophermart/internal/errs"
+	"github.com/etoneja/go-gophermart/internal/httperr"
 	"github.com/etoneja/go-gophermart/internal/models"
 	"github.com/etoneja/go-gophermart/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -14,7 +15,7 @@ import (
 func (h *Handlers) CreateOrderHandler(c *gin.Context) {
 	body, err := c.GetRawData()
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+		c.JSON(http.StatusBadRequest, httperr.Response(c, "failed to read request body"))
 		return
 	}
 
@@ -22,27 +23,28 @@ func (h *Handlers) CreateOrderHandler(c *gin.Context) {
 
 	isOrderIDValid, err := utils.LuhnCheck(orderID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		c.JSON(http.StatusBadRequest, httperr.Response(c, err.Error()))
 		return
 	}
 
 	if !isOrderIDValid {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "order id not in luhn format"})
+		c.JSON(http.StatusUnprocessableEntity, httperr.Response(c, "order id not in luhn format"))
 		return
 	}
 
 	user, err := GetCurrentUser(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.JSON(http.StatusUnauthorized, httperr.Response(c, err.Error()))
 		return
 	}
 
 	order := &models.OrderModel{
-		ID:        orderID,
-		UserID:    user.UUID,
-		Status:    models.OrderStatusNew,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:          orderID,
+		UserID:      user.UUID,
+		ProgramCode: GetProgramCode(c),
+		Status:      models.OrderStatusNew,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
 	}
 
 	createdOrder, err := h.svc.CreateOrGetOrder(c.Request.Context(), order)
@@ -50,20 +52,24 @@ func (h *Handlers) CreateOrderHandler(c *gin.Context) {
 		if errors.Is(err, errs.ErrOrderExists) {
 			oldOrder, err := h.svc.GetOrder(c.Request.Context(), order.ID)
 			if err != nil {
-				h.logger.Error().Err(err).Msg("err fetching old order on conflict")
-				c.JSON(http.StatusInternalServerError, gin.H{"error": http.StatusText(http.StatusInternalServerError)})
+				h.log(c).Error().Err(err).Msg("err fetching old order on conflict")
+				c.JSON(http.StatusInternalServerError, httperr.Response(c, http.StatusText(http.StatusInternalServerError)))
 				return
 			}
 			if order.UserID == oldOrder.UserID {
 				c.JSON(http.StatusOK, oldOrder.ToResponse())
 				return
 			}
-			c.JSON(http.StatusConflict, gin.H{"error": "order already created by another user"})
+			c.JSON(http.StatusConflict, httperr.Response(c, "order already created by another user"))
+			return
+		}
+		if errors.Is(err, errs.ErrProgramNotFound) {
+			c.JSON(http.StatusBadRequest, httperr.Response(c, "unknown loyalty program"))
 			return
 		}
 
-		h.logger.Error().Err(err).Msg("Failed to create order")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": http.StatusText(http.StatusInternalServerError)})
+		h.log(c).Error().Err(err).Msg("Failed to create order")
+		c.JSON(http.StatusInternalServerError, httperr.Response(c, http.StatusText(http.StatusInternalServerError)))
 		return
 	}
 
@@ -73,19 +79,19 @@ func (h *Handlers) CreateOrderHandler(c *gin.Context) {
 func (h *Handlers) GetOrdersHandler(c *gin.Context) {
 	user, err := GetCurrentUser(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.JSON(http.StatusUnauthorized, httperr.Response(c, err.Error()))
 		return
 	}
 
 	orders, err := h.svc.GetOrdersForUser(c.Request.Context(), user)
 	if err != nil {
-		h.logger.Error().Err(err).Msg("Failed to get orders")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": http.StatusText(http.StatusInternalServerError)})
+		h.log(c).Error().Err(err).Msg("Failed to get orders")
+		c.JSON(http.StatusInternalServerError, httperr.Response(c, http.StatusText(http.StatusInternalServerError)))
 		return
 	}
 
 	if len(orders) == 0 {
-		c.JSON(http.StatusNoContent, gin.H{"error": "no orders for current user"})
+		c.JSON(http.StatusNoContent, httperr.Response(c, "no orders for current user"))
 		return
 	}
 
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/etoneja/go-gophermart/internal/httperr"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) GetReferralsHandler(c *gin.Context) {
+	user, err := GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, httperr.Response(c, err.Error()))
+		return
+	}
+
+	referrals, err := h.svc.GetUserReferrals(c.Request.Context(), user.UUID)
+	if err != nil {
+		h.log(c).Error().Err(err).Msg("Failed to get referrals")
+		c.JSON(http.StatusInternalServerError, httperr.Response(c, http.StatusText(http.StatusInternalServerError)))
+		return
+	}
+
+	c.JSON(http.StatusOK, referrals.ToResponse(user.ReferralCode))
+}
@@ -5,45 +5,72 @@ import (
 	"net/http"
 
 	"github.com/etoneja/go-gophermart/internal/errs"
+	"github.com/etoneja/go-gophermart/internal/httperr"
 	"github.com/etoneja/go-gophermart/internal/models"
 	"github.com/etoneja/go-gophermart/internal/utils"
 	"github.com/gin-gonic/gin"
-	"github.com/rs/zerolog/log"
 )
 
+const IdempotencyKeyHeader = "Idempotency-Key"
+
 func (h *Handlers) GetBalanceHandler(c *gin.Context) {
 	user, err := GetCurrentUser(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.JSON(http.StatusUnauthorized, httperr.Response(c, err.Error()))
 		return
 	}
 
-	balance, err := h.svc.GetUserBalance(c.Request.Context(), user.UUID)
+	balance, err := h.svc.GetUserBalance(c.Request.Context(), user.UUID, GetProgramCode(c))
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to get balance")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": http.StatusText(http.StatusInternalServerError)})
+		if errors.Is(err, errs.ErrProgramNotFound) {
+			c.JSON(http.StatusBadRequest, httperr.Response(c, "unknown loyalty program"))
+			return
+		}
+		h.log(c).Error().Err(err).Msg("Failed to get balance")
+		c.JSON(http.StatusInternalServerError, httperr.Response(c, http.StatusText(http.StatusInternalServerError)))
 		return
 	}
 
 	c.JSON(http.StatusOK, balance.ToResponse())
 }
 
+func (h *Handlers) GetBalancesHandler(c *gin.Context) {
+	user, err := GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, httperr.Response(c, err.Error()))
+		return
+	}
+
+	balances, err := h.svc.GetUserBalances(c.Request.Context(), user.UUID)
+	if err != nil {
+		h.log(c).Error().Err(err).Msg("Failed to get balances")
+		c.JSON(http.StatusInternalServerError, httperr.Response(c, http.StatusText(http.StatusInternalServerError)))
+		return
+	}
+
+	c.JSON(http.StatusOK, balances.ToResponse())
+}
+
 func (h *Handlers) GetWithdrawalsHandler(c *gin.Context) {
 	user, err := GetCurrentUser(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.JSON(http.StatusUnauthorized, httperr.Response(c, err.Error()))
 		return
 	}
 
-	withdrawals, err := h.svc.GetUserWithdrawals(c.Request.Context(), user.UUID)
+	withdrawals, err := h.svc.GetUserWithdrawals(c.Request.Context(), user.UUID, GetProgramCode(c))
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to get withdrawals")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": http.StatusText(http.StatusInternalServerError)})
+		if errors.Is(err, errs.ErrProgramNotFound) {
+			c.JSON(http.StatusBadRequest, httperr.Response(c, "unknown loyalty program"))
+			return
+		}
+		h.log(c).Error().Err(err).Msg("Failed to get withdrawals")
+		c.JSON(http.StatusInternalServerError, httperr.Response(c, http.StatusText(http.StatusInternalServerError)))
 		return
 	}
 
 	if len(withdrawals) == 0 {
-		c.JSON(http.StatusNoContent, gin.H{"error": "no withdrawals for current user"})
+		c.JSON(http.StatusNoContent, httperr.Response(c, "no withdrawals for current user"))
 		return
 	}
 
@@ -54,27 +81,24 @@ func (h *Handlers) CreateWithdrawHandler(c *gin.Context) {
 	var req models.WithdrawRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_request",
-			"message": err.Error(),
-		})
+		c.JSON(http.StatusBadRequest, httperr.Response(c, "invalid request: "+err.Error()))
 		return
 	}
 
 	isOrderIDValid, err := utils.LuhnCheck(req.OrderID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "bad orderID format"})
+		c.JSON(http.StatusBadRequest, httperr.Response(c, "bad orderID format"))
 		return
 	}
 
 	if !isOrderIDValid {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "order id not in luhn format"})
+		c.JSON(http.StatusUnprocessableEntity, httperr.Response(c, "order id not in luhn format"))
 		return
 	}
 
 	user, err := GetCurrentUser(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.JSON(http.StatusUnauthorized, httperr.Response(c, err.Error()))
 		return
 	}
 
@@ -83,13 +107,101 @@ func (h *Handlers) CreateWithdrawHandler(c *gin.Context) {
 	err = h.svc.CreateWithdraw(c.Request.Context(), withdraw)
 	if err != nil {
 		if errors.Is(err, errs.ErrInsufficientFunds) {
-			c.JSON(http.StatusPaymentRequired, gin.H{"error": "insufficient funds"})
+			c.JSON(http.StatusPaymentRequired, httperr.Response(c, "insufficient funds"))
+			return
+		}
+		if errors.Is(err, errs.ErrProgramNotFound) {
+			c.JSON(http.StatusBadRequest, httperr.Response(c, "unknown loyalty program"))
 			return
 		}
-		h.logger.Error().Err(err).Msg("Failed to create withdraw")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": http.StatusText(http.StatusInternalServerError)})
+		h.log(c).Error().Err(err).Msg("Failed to create withdraw")
+		c.JSON(http.StatusInternalServerError, httperr.Response(c, http.StatusText(http.StatusInternalServerError)))
 		return
 	}
 
 	c.JSON(http.StatusOK, withdraw.ToResponse())
 }
+
+func (h *Handlers) CreateTransferHandler(c *gin.Context) {
+	var req models.TransferRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httperr.Response(c, "invalid request: "+err.Error()))
+		return
+	}
+
+	idempotencyKey := c.GetHeader(IdempotencyKeyHeader)
+	if len(idempotencyKey) > 255 {
+		c.JSON(http.StatusBadRequest, httperr.Response(c, "idempotency key too long"))
+		return
+	}
+
+	user, err := GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, httperr.Response(c, err.Error()))
+		return
+	}
+
+	transfer, err := h.svc.CreateTransfer(c.Request.Context(), req.ToModel(user.UUID, idempotencyKey))
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrInsufficientFunds):
+			c.JSON(http.StatusPaymentRequired, httperr.Response(c, "insufficient funds"))
+		case errors.Is(err, errs.ErrRecipientNotFound):
+			c.JSON(http.StatusNotFound, httperr.Response(c, "recipient not found"))
+		case errors.Is(err, errs.ErrSelfTransfer):
+			c.JSON(http.StatusBadRequest, httperr.Response(c, "can't transfer to yourself"))
+		case errors.Is(err, errs.ErrProgramNotFound):
+			c.JSON(http.StatusBadRequest, httperr.Response(c, "unknown loyalty program"))
+		case errors.Is(err, errs.ErrTransferLimitExceeded):
+			c.JSON(http.StatusUnprocessableEntity, httperr.Response(c, "daily transfer limit exceeded"))
+		case errors.Is(err, errs.ErrIdempotencyKeyReused):
+			c.JSON(http.StatusConflict, httperr.Response(c, "idempotency key already used for another transfer"))
+		default:
+			h.log(c).Error().Err(err).Msg("Failed to create transfer")
+			c.JSON(http.StatusInternalServerError, httperr.Response(c, http.StatusText(http.StatusInternalServerError)))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer.ToResponse())
+}
+
+func (h *Handlers) ConvertBalanceHandler(c *gin.Context) {
+	var req models.ConversionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httperr.Response(c, "invalid request: "+err.Error()))
+		return
+	}
+
+	user, err := GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, httperr.Response(c, err.Error()))
+		return
+	}
+
+	conversion := req.ToModel(user.UUID)
+
+	err = h.svc.ConvertBalance(c.Request.Context(), conversion)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrInsufficientFunds):
+			c.JSON(http.StatusPaymentRequired, httperr.Response(c, "insufficient funds"))
+		case errors.Is(err, errs.ErrProgramNotFound):
+			c.JSON(http.StatusBadRequest, httperr.Response(c, "unknown loyalty program"))
+		case errors.Is(err, errs.ErrSameProgram):
+			c.JSON(http.StatusBadRequest, httperr.Response(c, "can't convert to the same program"))
+		case errors.Is(err, errs.ErrConversionTooSmall):
+			c.JSON(http.StatusUnprocessableEntity, httperr.Response(c, "amount too small to convert"))
+		case errors.Is(err, errs.ErrConversionOutOfRange):
+			c.JSON(http.StatusUnprocessableEntity, httperr.Response(c, "converted amount out of range"))
+		default:
+			h.log(c).Error().Err(err).Msg("Failed to convert balance")
+			c.JSON(http.StatusInternalServerError, httperr.Response(c, http.StatusText(http.StatusInternalServerError)))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, conversion.ToResponse())
+}
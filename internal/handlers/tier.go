@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/etoneja/go-gophermart/internal/httperr"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) GetTierHandler(c *gin.Context) {
+	user, err := GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, httperr.Response(c, err.Error()))
+		return
+	}
+
+	tier, err := h.svc.GetUserTier(c.Request.Context(), user.UUID)
+	if err != nil {
+		h.log(c).Error().Err(err).Msg("Failed to get tier")
+		c.JSON(http.StatusInternalServerError, httperr.Response(c, http.StatusText(http.StatusInternalServerError)))
+		return
+	}
+
+	c.JSON(http.StatusOK, tier.ToResponse())
+}
@@ -0,0 +1,135 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const checkTimeout = 3 * time.Second
+
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	StatusFailing  = "failing"
+	StatusDraining = "draining"
+)
+
+// Check is a named readiness check. A failing critical check makes the
+// process unready, other failures only degrade it.
+type Check struct {
+	Name     string
+	Critical bool
+	Run      func(ctx context.Context) error
+}
+
+type CheckResult struct {
+	Status   string `json:"status"`
+	Critical bool   `json:"critical"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Checker serves the liveness and readiness probes.
+type Checker struct {
+	checks   []Check
+	draining atomic.Bool
+}
+
+func NewChecker(checks ...Check) *Checker {
+	return &Checker{checks: checks}
+}
+
+// SetDraining makes readiness fail from now on, so the process is taken out
+// of rotation before its connections are drained.
+func (c *Checker) SetDraining() {
+	c.draining.Store(true)
+}
+
+// Ready runs all checks concurrently.
+func (c *Checker) Ready(ctx context.Context) Report {
+	if c.draining.Load() {
+		return Report{Status: StatusDraining}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	results := make([]CheckResult, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = runCheck(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(c.checks))}
+	for i, check := range c.checks {
+		result := results[i]
+		report.Checks[check.Name] = result
+		if result.Status == StatusOK {
+			continue
+		}
+		if check.Critical {
+			report.Status = StatusFailing
+		} else if report.Status == StatusOK {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+func runCheck(ctx context.Context, check Check) CheckResult {
+	start := time.Now()
+	err := check.Run(ctx)
+
+	result := CheckResult{
+		Status:   StatusOK,
+		Critical: check.Critical,
+		Duration: time.Since(start).String(),
+	}
+	if err != nil {
+		result.Status = StatusFailing
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// LivenessHandler answers 200 while the process is up.
+func (c *Checker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeReport(w, http.StatusOK, Report{Status: StatusOK})
+	})
+}
+
+// ReadinessHandler answers 200 when all critical checks pass and 503 with
+// the same breakdown otherwise.
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Ready(r.Context())
+
+		code := http.StatusOK
+		if report.Status == StatusFailing || report.Status == StatusDraining {
+			code = http.StatusServiceUnavailable
+		}
+		writeReport(w, code, report)
+	})
+}
+
+func writeReport(w http.ResponseWriter, code int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,96 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func okCheck(context.Context) error { return nil }
+
+func failCheck(context.Context) error { return errors.New("down") }
+
+func TestChecker_ReadinessHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		checks     []Check
+		draining   bool
+		wantCode   int
+		wantStatus string
+	}{
+		{
+			name: "all ok",
+			checks: []Check{
+				{Name: "db", Critical: true, Run: okCheck},
+				{Name: "accrual", Run: okCheck},
+			},
+			wantCode:   http.StatusOK,
+			wantStatus: StatusOK,
+		},
+		{
+			name: "non-critical failure degrades",
+			checks: []Check{
+				{Name: "db", Critical: true, Run: okCheck},
+				{Name: "accrual", Run: failCheck},
+			},
+			wantCode:   http.StatusOK,
+			wantStatus: StatusDegraded,
+		},
+		{
+			name: "critical failure fails",
+			checks: []Check{
+				{Name: "db", Critical: true, Run: failCheck},
+				{Name: "accrual", Run: failCheck},
+			},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: StatusFailing,
+		},
+		{
+			name: "draining",
+			checks: []Check{
+				{Name: "db", Critical: true, Run: okCheck},
+			},
+			draining:   true,
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: StatusDraining,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(tt.checks...)
+			if tt.draining {
+				checker.SetDraining()
+			}
+
+			rec := httptest.NewRecorder()
+			checker.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+
+			var report Report
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+			assert.Equal(t, tt.wantStatus, report.Status)
+			if !tt.draining {
+				assert.Len(t, report.Checks, len(tt.checks))
+			}
+		})
+	}
+}
+
+func TestChecker_ReadinessReportsErrors(t *testing.T) {
+	checker := NewChecker(Check{Name: "db", Critical: true, Run: failCheck})
+
+	report := checker.Ready(context.Background())
+
+	require.Contains(t, report.Checks, "db")
+	assert.Equal(t, StatusFailing, report.Checks["db"].Status)
+	assert.Equal(t, "down", report.Checks["db"].Error)
+	assert.True(t, report.Checks["db"].Critical)
+}
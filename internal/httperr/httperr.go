@@ -0,0 +1,18 @@
+// Package httperr builds the error responses shared by the handlers and the
+// middlewares.
+package httperr
+
+import (
+	"github.com/etoneja/go-gophermart/internal/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// Response is the body of every error response. It echoes the request ID, so
+// a client can quote it when reporting a problem.
+func Response(c *gin.Context, message string) gin.H {
+	resp := gin.H{"error": message}
+	if requestID := logging.RequestID(c.Request.Context()); requestID != "" {
+		resp["request_id"] = requestID
+	}
+	return resp
+}
@@ -0,0 +1,73 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Channel is notified with the job kind whenever a job is enqueued.
+const Channel = "jobs"
+
+const DefaultMaxAttempts = 10
+
+var ErrInvalidPayload = errors.New("invalid job payload")
+
+type Job struct {
+	ID          int64
+	Kind        string
+	Payload     json.RawMessage
+	UniqueKey   *string
+	Attempts    int
+	MaxAttempts int
+	LastError   *string
+	CreatedAt   time.Time
+}
+
+// EnqueueParams describes a job to enqueue. Payload is stored as JSON. Jobs
+// sharing a UniqueKey are enqueued once: enqueueing again only moves the
+// pending job's RunAt earlier, or runs it once more if it is running right
+// now. With IfMissing set an existing job is left alone, so the job is only
+// created when there is none or it has been dead-lettered. A zero RunAt means
+// now and a zero MaxAttempts DefaultMaxAttempts.
+type EnqueueParams struct {
+	Kind        string
+	Payload     any
+	UniqueKey   string
+	IfMissing   bool
+	RunAt       time.Time
+	MaxAttempts int
+}
+
+// Handler runs a job. Returning an error retries the job with backoff until
+// it runs out of attempts and is dead-lettered.
+type Handler func(ctx context.Context, job *Job) error
+
+// Typed adapts a handler taking the decoded payload. Jobs with a payload that
+// can't be decoded into T are dead-lettered right away.
+func Typed[T any](fn func(ctx context.Context, payload T) error) Handler {
+	return func(ctx context.Context, job *Job) error {
+		var payload T
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+		}
+		return fn(ctx, payload)
+	}
+}
+
+type snoozeError struct {
+	delay time.Duration
+}
+
+func (e *snoozeError) Error() string {
+	return fmt.Sprintf("job snoozed for %s", e.delay)
+}
+
+// Snooze is returned by a handler to run the job again after delay without
+// counting it as a failed attempt, which makes a job with a UniqueKey
+// recurring.
+func Snooze(delay time.Duration) error {
+	return &snoozeError{delay: max(delay, 0)}
+}
@@ -0,0 +1,96 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/utils"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+const (
+	listenerBackoffBase = time.Second
+	listenerBackoffMax  = 30 * time.Second
+)
+
+// Listener keeps a dedicated connection LISTENing on a Postgres channel and
+// signals Notifications, coalescing bursts into a single wake-up. It also
+// signals after every (re)connect, since events may have been missed while
+// the connection was down.
+type Listener struct {
+	connConfig *pgx.ConnConfig
+	channel    string
+	logger     zerolog.Logger
+	notify     chan struct{}
+}
+
+func NewListener(connConfig *pgx.ConnConfig, channel string, logger zerolog.Logger) *Listener {
+	return &Listener{
+		connConfig: connConfig,
+		channel:    channel,
+		logger:     logger,
+		notify:     make(chan struct{}, 1),
+	}
+}
+
+func (l *Listener) Notifications() <-chan struct{} {
+	return l.notify
+}
+
+// Run listens until ctx is done, reconnecting with backoff on errors.
+func (l *Listener) Run(ctx context.Context) {
+	attempt := 0
+	for {
+		connected, err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			attempt = 0
+		}
+		attempt++
+
+		delay := utils.Backoff(attempt, listenerBackoffBase, listenerBackoffMax)
+		l.logger.Warn().
+			Err(err).
+			Str("channel", l.channel).
+			Dur("retryIn", delay).
+			Msg("Listener connection lost")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (l *Listener) listen(ctx context.Context) (bool, error) {
+	conn, err := pgx.ConnectConfig(ctx, l.connConfig)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+		return false, err
+	}
+
+	l.logger.Info().Str("channel", l.channel).Msg("Listening for notifications")
+	l.wake()
+
+	for {
+		if _, err := conn.WaitForNotification(ctx); err != nil {
+			return true, err
+		}
+		l.wake()
+	}
+}
+
+func (l *Listener) wake() {
+	select {
+	case l.notify <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,272 @@
+package jobs
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrLeaseLost = errors.New("job lease lost")
+
+// Queue stores jobs in the jobs table. Workers lease due jobs with
+// FOR UPDATE SKIP LOCKED, so any number of them can share the table.
+//
+// While a job is leased its run_at is set to the lease expiry: a worker that
+// dies mid-job leaves it due again once the lease runs out, and an enqueue of
+// the same unique key moving run_at earlier is noticed when the job
+// finishes.
+type Queue struct {
+	db *pgxpool.Pool
+}
+
+func NewQueue(db *pgxpool.Pool) *Queue {
+	return &Queue{db: db}
+}
+
+// Enqueue adds a job within tx, the job becomes visible and workers are
+// notified when tx commits. A nil tx enqueues in a transaction of its own.
+// It returns 0 when IfMissing is set and the job already exists.
+func (q *Queue) Enqueue(ctx context.Context, tx pgx.Tx, params EnqueueParams) (int64, error) {
+	if tx == nil {
+		var id int64
+		err := db.WithTx(ctx, q.db, func(txCtx context.Context) error {
+			var err error
+			id, err = q.Enqueue(txCtx, db.GetTxFromContext(txCtx), params)
+			return err
+		})
+		return id, err
+	}
+
+	payload := []byte("{}")
+	if params.Payload != nil {
+		var err error
+		if payload, err = json.Marshal(params.Payload); err != nil {
+			return 0, fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+		}
+	}
+
+	now := time.Now()
+	runAt := params.RunAt
+	if runAt.IsZero() {
+		runAt = now
+	}
+	var uniqueKey *string
+	if params.UniqueKey != "" {
+		uniqueKey = &params.UniqueKey
+	}
+
+	onConflict := `
+		DO UPDATE
+		SET
+			run_at = LEAST(jobs.run_at, EXCLUDED.run_at),
+			updated_at = EXCLUDED.updated_at
+	`
+	if params.IfMissing {
+		onConflict = `DO NOTHING`
+	}
+
+	query := `
+		INSERT INTO jobs (
+			kind,
+			payload,
+			unique_key,
+			max_attempts,
+			run_at,
+			created_at,
+			updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		ON CONFLICT (unique_key) WHERE dead_lettered_at IS NULL ` + onConflict + `
+		RETURNING id
+	`
+
+	var id int64
+	err := tx.QueryRow(
+		ctx,
+		query,
+		params.Kind,
+		payload,
+		uniqueKey,
+		cmp.Or(params.MaxAttempts, DefaultMaxAttempts),
+		runAt,
+		now).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// IfMissing and the job already exists.
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT pg_notify($1, $2)`, Channel, params.Kind); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// dequeue leases up to limit due jobs of the given kinds to workerID and
+// counts the attempt.
+func (q *Queue) dequeue(ctx context.Context, workerID string, kinds []string, limit int, lease time.Duration) ([]*Job, error) {
+	query := `
+		UPDATE jobs
+		SET
+			locked_by = $1,
+			locked_until = $2,
+			run_at = $2,
+			attempts = attempts + 1,
+			updated_at = $3
+		WHERE id IN (
+			SELECT id
+			FROM jobs
+			WHERE
+				kind = any($4)
+				AND dead_lettered_at IS NULL
+				AND run_at <= $3
+				AND (locked_until IS NULL OR locked_until <= $3)
+			ORDER BY run_at, id
+			FOR UPDATE SKIP LOCKED
+			LIMIT $5
+		)
+		RETURNING
+			id,
+			kind,
+			payload,
+			unique_key,
+			attempts,
+			max_attempts,
+			last_error,
+			created_at
+	`
+
+	now := time.Now()
+	rows, err := q.db.Query(ctx, query, workerID, now.Add(lease), now, kinds, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		var job Job
+		err := rows.Scan(
+			&job.ID,
+			&job.Kind,
+			&job.Payload,
+			&job.UniqueKey,
+			&job.Attempts,
+			&job.MaxAttempts,
+			&job.LastError,
+			&job.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, &job)
+	}
+	return jobs, rows.Err()
+}
+
+// complete deletes a finished job, unless it was enqueued again while
+// running, then it is released to run once more.
+func (q *Queue) complete(ctx context.Context, workerID string, job *Job) error {
+	return db.WithTx(ctx, q.db, func(txCtx context.Context) error {
+		tx := db.GetTxFromContext(txCtx)
+
+		res, err := tx.Exec(txCtx, `
+			DELETE FROM jobs
+			WHERE id = $1 AND locked_by = $2 AND run_at >= locked_until
+		`, job.ID, workerID)
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 1 {
+			return nil
+		}
+
+		res, err = tx.Exec(txCtx, `
+			UPDATE jobs
+			SET
+				locked_by = NULL,
+				locked_until = NULL,
+				attempts = 0,
+				last_error = NULL,
+				updated_at = $3
+			WHERE id = $1 AND locked_by = $2
+		`, job.ID, workerID, time.Now())
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() != 1 {
+			return ErrLeaseLost
+		}
+		return nil
+	})
+}
+
+// reschedule releases the job to run again at runAt, or as requested by an
+// enqueue while it was running. A retry keeps the attempt the lease counted,
+// a snooze gives it back.
+func (q *Queue) reschedule(ctx context.Context, workerID string, job *Job, runAt time.Time, countAttempt bool, lastError *string) error {
+	query := `
+		UPDATE jobs
+		SET
+			locked_by = NULL,
+			locked_until = NULL,
+			run_at = CASE WHEN run_at < locked_until THEN run_at ELSE $3 END,
+			attempts = CASE WHEN $4 THEN attempts ELSE attempts - 1 END,
+			last_error = COALESCE($5, last_error),
+			updated_at = $6
+		WHERE id = $1 AND locked_by = $2
+	`
+
+	res, err := q.db.Exec(ctx, query, job.ID, workerID, runAt, countAttempt, lastError, time.Now())
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() != 1 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// deadLetter keeps the job for inspection. Only the latest dead-lettered job
+// of a unique key is kept, older ones are deleted, so a unique job that keeps
+// getting re-created doesn't pile up rows.
+func (q *Queue) deadLetter(ctx context.Context, workerID string, job *Job, lastError string) error {
+	return db.WithTx(ctx, q.db, func(txCtx context.Context) error {
+		tx := db.GetTxFromContext(txCtx)
+
+		now := time.Now()
+		res, err := tx.Exec(txCtx, `
+			UPDATE jobs
+			SET
+				locked_by = NULL,
+				locked_until = NULL,
+				last_error = $3,
+				dead_lettered_at = $4,
+				updated_at = $4
+			WHERE id = $1 AND locked_by = $2
+		`, job.ID, workerID, lastError, now)
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() != 1 {
+			return ErrLeaseLost
+		}
+
+		if job.UniqueKey == nil {
+			return nil
+		}
+		_, err = tx.Exec(txCtx, `
+			DELETE FROM jobs
+			WHERE unique_key = $1 AND dead_lettered_at IS NOT NULL AND id != $2
+		`, *job.UniqueKey, job.ID)
+		return err
+	})
+}
@@ -0,0 +1,230 @@
+package jobs
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/db"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestQueue runs the queue against the database in TEST_DATABASE_URL,
+// the queue SQL tests are skipped without one. The jobs table is emptied
+// before each test.
+func newTestQueue(t *testing.T) *Queue {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := db.NewDB(ctx, dsn)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	require.NoError(t, db.NewMigrator(pool, zerolog.Nop()).Migrate(ctx))
+	_, err = pool.Exec(ctx, `TRUNCATE jobs`)
+	require.NoError(t, err)
+
+	return NewQueue(pool)
+}
+
+type jobRow struct {
+	attempts     int
+	lockedBy     *string
+	lastError    *string
+	deadLettered bool
+	// due reports whether run_at has passed at the time the row was read.
+	due bool
+}
+
+func getJobRow(t *testing.T, q *Queue, id int64) jobRow {
+	t.Helper()
+
+	var row jobRow
+	err := q.db.QueryRow(context.Background(), `
+		SELECT attempts, locked_by, last_error, dead_lettered_at IS NOT NULL, run_at <= $2
+		FROM jobs
+		WHERE id = $1
+	`, id, time.Now()).Scan(&row.attempts, &row.lockedBy, &row.lastError, &row.deadLettered, &row.due)
+	require.NoError(t, err)
+	return row
+}
+
+func countJobs(t *testing.T, q *Queue, uniqueKey string) int {
+	t.Helper()
+
+	var n int
+	err := q.db.QueryRow(context.Background(),
+		`SELECT COUNT(*) FROM jobs WHERE unique_key = $1`, uniqueKey).Scan(&n)
+	require.NoError(t, err)
+	return n
+}
+
+func dequeueOne(t *testing.T, q *Queue, workerID string) *Job {
+	t.Helper()
+
+	jobs, err := q.dequeue(context.Background(), workerID, []string{"test"}, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	return jobs[0]
+}
+
+func TestQueue_Dequeue(t *testing.T) {
+	q := newTestQueue(t)
+	ctx := context.Background()
+
+	dueID, err := q.Enqueue(ctx, nil, EnqueueParams{Kind: "test", Payload: testPayload{Value: "ok"}})
+	require.NoError(t, err)
+	_, err = q.Enqueue(ctx, nil, EnqueueParams{Kind: "test", RunAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	_, err = q.Enqueue(ctx, nil, EnqueueParams{Kind: "other"})
+	require.NoError(t, err)
+
+	job := dequeueOne(t, q, "worker-1")
+	assert.Equal(t, dueID, job.ID)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Equal(t, DefaultMaxAttempts, job.MaxAttempts)
+	assert.JSONEq(t, `{"value":"ok"}`, string(job.Payload))
+
+	row := getJobRow(t, q, job.ID)
+	require.NotNil(t, row.lockedBy)
+	assert.Equal(t, "worker-1", *row.lockedBy)
+	assert.False(t, row.due, "a leased job is due again only when the lease runs out")
+
+	jobs, err := q.dequeue(ctx, "worker-2", []string{"test"}, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+func TestQueue_CompleteDeletesJob(t *testing.T) {
+	q := newTestQueue(t)
+	ctx := context.Background()
+
+	_, err := q.Enqueue(ctx, nil, EnqueueParams{Kind: "test", UniqueKey: "unique"})
+	require.NoError(t, err)
+
+	job := dequeueOne(t, q, "worker-1")
+	assert.ErrorIs(t, q.complete(ctx, "worker-2", job), ErrLeaseLost)
+	require.NoError(t, q.complete(ctx, "worker-1", job))
+	assert.Zero(t, countJobs(t, q, "unique"))
+}
+
+func TestQueue_EnqueueWhileRunning(t *testing.T) {
+	q := newTestQueue(t)
+	ctx := context.Background()
+
+	id, err := q.Enqueue(ctx, nil, EnqueueParams{Kind: "test", UniqueKey: "unique"})
+	require.NoError(t, err)
+	job := dequeueOne(t, q, "worker-1")
+
+	againID, err := q.Enqueue(ctx, nil, EnqueueParams{Kind: "test", UniqueKey: "unique"})
+	require.NoError(t, err)
+	assert.Equal(t, id, againID)
+
+	require.NoError(t, q.complete(ctx, "worker-1", job))
+
+	row := getJobRow(t, q, id)
+	assert.Nil(t, row.lockedBy)
+	assert.Zero(t, row.attempts)
+	assert.True(t, row.due)
+
+	job = dequeueOne(t, q, "worker-1")
+	assert.Equal(t, id, job.ID)
+	require.NoError(t, q.complete(ctx, "worker-1", job))
+	assert.Zero(t, countJobs(t, q, "unique"))
+}
+
+func TestQueue_EnqueueIfMissing(t *testing.T) {
+	q := newTestQueue(t)
+	ctx := context.Background()
+
+	id, err := q.Enqueue(ctx, nil, EnqueueParams{Kind: "test", UniqueKey: "unique", RunAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+
+	againID, err := q.Enqueue(ctx, nil, EnqueueParams{Kind: "test", UniqueKey: "unique", IfMissing: true})
+	require.NoError(t, err)
+	assert.Zero(t, againID)
+	assert.False(t, getJobRow(t, q, id).due, "IfMissing must not move run_at")
+
+	_, err = q.Enqueue(ctx, nil, EnqueueParams{Kind: "test", UniqueKey: "unique"})
+	require.NoError(t, err)
+	assert.True(t, getJobRow(t, q, id).due)
+}
+
+func TestQueue_Reschedule(t *testing.T) {
+	q := newTestQueue(t)
+	ctx := context.Background()
+
+	id, err := q.Enqueue(ctx, nil, EnqueueParams{Kind: "test"})
+	require.NoError(t, err)
+
+	job := dequeueOne(t, q, "worker-1")
+	require.NoError(t, q.reschedule(ctx, "worker-1", job, time.Now().Add(-time.Second), false, nil))
+
+	row := getJobRow(t, q, id)
+	assert.Zero(t, row.attempts, "a snooze gives the attempt back")
+	assert.Nil(t, row.lockedBy)
+	assert.True(t, row.due)
+
+	job = dequeueOne(t, q, "worker-1")
+	lastError := "boom"
+	assert.ErrorIs(t, q.reschedule(ctx, "worker-2", job, time.Now(), true, &lastError), ErrLeaseLost)
+	require.NoError(t, q.reschedule(ctx, "worker-1", job, time.Now().Add(time.Hour), true, &lastError))
+
+	row = getJobRow(t, q, id)
+	assert.Equal(t, 1, row.attempts)
+	require.NotNil(t, row.lastError)
+	assert.Equal(t, "boom", *row.lastError)
+	assert.False(t, row.due)
+}
+
+func TestQueue_RescheduleKeepsEnqueueWhileRunning(t *testing.T) {
+	q := newTestQueue(t)
+	ctx := context.Background()
+
+	id, err := q.Enqueue(ctx, nil, EnqueueParams{Kind: "test", UniqueKey: "unique"})
+	require.NoError(t, err)
+	job := dequeueOne(t, q, "worker-1")
+
+	_, err = q.Enqueue(ctx, nil, EnqueueParams{Kind: "test", UniqueKey: "unique"})
+	require.NoError(t, err)
+	require.NoError(t, q.reschedule(ctx, "worker-1", job, time.Now().Add(time.Hour), false, nil))
+
+	assert.True(t, getJobRow(t, q, id).due)
+}
+
+func TestQueue_DeadLetter(t *testing.T) {
+	q := newTestQueue(t)
+	ctx := context.Background()
+
+	firstID, err := q.Enqueue(ctx, nil, EnqueueParams{Kind: "test", UniqueKey: "unique"})
+	require.NoError(t, err)
+	job := dequeueOne(t, q, "worker-1")
+	assert.ErrorIs(t, q.deadLetter(ctx, "worker-2", job, "boom"), ErrLeaseLost)
+	require.NoError(t, q.deadLetter(ctx, "worker-1", job, "boom"))
+
+	row := getJobRow(t, q, firstID)
+	assert.True(t, row.deadLettered)
+	assert.Nil(t, row.lockedBy)
+
+	jobs, err := q.dequeue(ctx, "worker-1", []string{"test"}, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+
+	secondID, err := q.Enqueue(ctx, nil, EnqueueParams{Kind: "test", UniqueKey: "unique", IfMissing: true})
+	require.NoError(t, err)
+	require.NotZero(t, secondID)
+	assert.NotEqual(t, firstID, secondID)
+
+	job = dequeueOne(t, q, "worker-1")
+	require.NoError(t, q.deadLetter(ctx, "worker-1", job, "boom"))
+	assert.Equal(t, 1, countJobs(t, q, "unique"), "older dead-lettered jobs of the key are deleted")
+	assert.True(t, getJobRow(t, q, secondID).deadLettered)
+}
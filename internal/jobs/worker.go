@@ -0,0 +1,260 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/logging"
+	"github.com/etoneja/go-gophermart/internal/tracing"
+	"github.com/etoneja/go-gophermart/internal/utils"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = tracing.Tracer("jobs")
+
+const (
+	defaultConcurrency    = 4
+	defaultPollInterval   = 5 * time.Second
+	defaultLease          = 5 * time.Minute
+	defaultRetryBaseDelay = 10 * time.Second
+	defaultRetryMaxDelay  = time.Hour
+)
+
+type store interface {
+	dequeue(ctx context.Context, workerID string, kinds []string, limit int, lease time.Duration) ([]*Job, error)
+	complete(ctx context.Context, workerID string, job *Job) error
+	reschedule(ctx context.Context, workerID string, job *Job, runAt time.Time, countAttempt bool, lastError *string) error
+	deadLetter(ctx context.Context, workerID string, job *Job, lastError string) error
+}
+
+type Option func(*Worker)
+
+func WithConcurrency(n int) Option {
+	return func(w *Worker) {
+		if n > 0 {
+			w.concurrency = n
+		}
+	}
+}
+
+func WithPollInterval(d time.Duration) Option {
+	return func(w *Worker) {
+		if d > 0 {
+			w.pollInterval = d
+		}
+	}
+}
+
+// WithLease sets how long a job is leased to the worker, jobs outliving it
+// may run twice.
+func WithLease(d time.Duration) Option {
+	return func(w *Worker) {
+		if d > 0 {
+			w.lease = d
+		}
+	}
+}
+
+func WithRetryBackoff(baseDelay, maxDelay time.Duration) Option {
+	return func(w *Worker) {
+		w.retryBaseDelay = baseDelay
+		w.retryMaxDelay = maxDelay
+	}
+}
+
+// Worker runs jobs of the registered kinds, up to concurrency at a time. It
+// polls every pollInterval and as soon as Channel is notified or a running
+// job finishes.
+type Worker struct {
+	store          store
+	id             string
+	handlers       map[string]Handler
+	concurrency    int
+	pollInterval   time.Duration
+	lease          time.Duration
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
+	listener       *Listener
+	wakeCh         chan struct{}
+	wg             sync.WaitGroup
+	logger         zerolog.Logger
+}
+
+func NewWorker(queue *Queue, logger zerolog.Logger, opts ...Option) *Worker {
+	w := &Worker{
+		store:          queue,
+		id:             utils.NewWorkerID(),
+		handlers:       make(map[string]Handler),
+		concurrency:    defaultConcurrency,
+		pollInterval:   defaultPollInterval,
+		lease:          defaultLease,
+		retryBaseDelay: defaultRetryBaseDelay,
+		retryMaxDelay:  defaultRetryMaxDelay,
+		listener:       NewListener(queue.db.Config().ConnConfig.Copy(), Channel, logger),
+		wakeCh:         make(chan struct{}, 1),
+		logger:         logger,
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// Register sets the handler for a job kind, it must be called before Run.
+func (w *Worker) Register(kind string, handler Handler) {
+	if _, exists := w.handlers[kind]; exists {
+		panic(fmt.Sprintf("jobs: handler for %q registered twice", kind))
+	}
+	w.handlers[kind] = handler
+}
+
+// Wake makes the worker poll for due jobs right away.
+func (w *Worker) Wake() {
+	select {
+	case w.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+func (w *Worker) Run(ctx context.Context) {
+	w.wg.Add(1)
+	defer w.wg.Done()
+
+	var notifications <-chan struct{}
+	if w.listener != nil {
+		notifications = w.listener.Notifications()
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.listener.Run(ctx)
+		}()
+	}
+
+	kinds := slices.Sorted(maps.Keys(w.handlers))
+	slots := make(chan struct{}, w.concurrency)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info().Msg("Jobs worker stopped")
+			return
+		case <-notifications:
+		case <-w.wakeCh:
+		case <-timer.C:
+		}
+
+		timer.Reset(w.pollInterval)
+
+		free := cap(slots) - len(slots)
+		if free == 0 || len(kinds) == 0 {
+			continue
+		}
+
+		jobs, err := w.store.dequeue(ctx, w.id, kinds, free, w.lease)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.logger.Error().Err(err).Msg("Failed to dequeue jobs")
+			}
+			continue
+		}
+
+		for _, job := range jobs {
+			slots <- struct{}{}
+			w.wg.Add(1)
+			go func() {
+				defer func() {
+					<-slots
+					w.wg.Done()
+					w.Wake()
+				}()
+				w.process(ctx, job)
+			}()
+		}
+	}
+}
+
+// Stop waits for Run and the running jobs to return after ctx is done.
+func (w *Worker) Stop() {
+	w.wg.Wait()
+}
+
+func (w *Worker) process(ctx context.Context, job *Job) {
+	logger := w.logger.With().
+		Int64("jobID", job.ID).
+		Str("kind", job.Kind).
+		Int("attempt", job.Attempts).
+		Logger()
+
+	// The outcome is stored even when ctx is cancelled by shutdown.
+	storeCtx := context.WithoutCancel(ctx)
+
+	if job.Attempts > job.MaxAttempts {
+		// The last attempt's worker died before recording the outcome.
+		w.storeOutcome(logger, w.store.deadLetter(storeCtx, w.id, job, "attempts exhausted"))
+		logger.Warn().Msg("Job dead-lettered, attempts exhausted")
+		return
+	}
+
+	// Handlers log through logging.FromContext with the job fields attached.
+	err := w.run(logging.WithLogger(ctx, logger), job)
+
+	var snooze *snoozeError
+	switch {
+	case err == nil:
+		w.storeOutcome(logger, w.store.complete(storeCtx, w.id, job))
+		logger.Debug().Msg("Job completed")
+	case errors.As(err, &snooze):
+		w.storeOutcome(logger, w.store.reschedule(storeCtx, w.id, job, time.Now().Add(snooze.delay), false, nil))
+		logger.Debug().Dur("delay", snooze.delay).Msg("Job snoozed")
+	case ctx.Err() != nil:
+		// Interrupted by shutdown, hand the job over to another worker.
+		w.storeOutcome(logger, w.store.reschedule(storeCtx, w.id, job, time.Now(), false, nil))
+		logger.Info().Msg("Job interrupted by shutdown")
+	case errors.Is(err, ErrInvalidPayload) || job.Attempts >= job.MaxAttempts:
+		w.storeOutcome(logger, w.store.deadLetter(storeCtx, w.id, job, err.Error()))
+		logger.Warn().Err(err).Msg("Job dead-lettered")
+	default:
+		msg := err.Error()
+		delay := utils.Backoff(job.Attempts, w.retryBaseDelay, w.retryMaxDelay)
+		w.storeOutcome(logger, w.store.reschedule(storeCtx, w.id, job, time.Now().Add(delay), true, &msg))
+		logger.Warn().Err(err).Dur("retryIn", delay).Msg("Job failed")
+	}
+}
+
+// run calls the job handler, turning a panic into an error.
+func (w *Worker) run(ctx context.Context, job *Job) (err error) {
+	ctx, span := tracer.Start(ctx, "job "+job.Kind, trace.WithAttributes(
+		attribute.Int64("job.id", job.ID),
+		attribute.Int("job.attempt", job.Attempts),
+	))
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v", p)
+		}
+		var snooze *snoozeError
+		if errors.As(err, &snooze) {
+			span.End()
+			return
+		}
+		tracing.End(span, err)
+	}()
+	return w.handlers[job.Kind](ctx, job)
+}
+
+func (w *Worker) storeOutcome(logger zerolog.Logger, err error) {
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to store job outcome")
+	}
+}
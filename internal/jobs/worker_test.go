@@ -0,0 +1,160 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type outcome struct {
+	kind         string
+	runAt        time.Time
+	countAttempt bool
+	lastError    string
+}
+
+type fakeStore struct {
+	mu       sync.Mutex
+	pending  []*Job
+	outcomes map[int64]outcome
+	done     chan struct{}
+}
+
+func newFakeStore(jobs ...*Job) *fakeStore {
+	return &fakeStore{
+		pending:  jobs,
+		outcomes: make(map[int64]outcome),
+		done:     make(chan struct{}, len(jobs)),
+	}
+}
+
+func (s *fakeStore) dequeue(_ context.Context, _ string, kinds []string, limit int, _ time.Duration) ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := min(limit, len(s.pending))
+	jobs := s.pending[:n]
+	s.pending = s.pending[n:]
+	for _, job := range jobs {
+		job.Attempts++
+	}
+	return jobs, nil
+}
+
+func (s *fakeStore) record(job *Job, o outcome) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outcomes[job.ID] = o
+	s.done <- struct{}{}
+	return nil
+}
+
+func (s *fakeStore) complete(_ context.Context, _ string, job *Job) error {
+	return s.record(job, outcome{kind: "complete"})
+}
+
+func (s *fakeStore) reschedule(_ context.Context, _ string, job *Job, runAt time.Time, countAttempt bool, lastError *string) error {
+	o := outcome{kind: "reschedule", runAt: runAt, countAttempt: countAttempt}
+	if lastError != nil {
+		o.lastError = *lastError
+	}
+	return s.record(job, o)
+}
+
+func (s *fakeStore) deadLetter(_ context.Context, _ string, job *Job, lastError string) error {
+	return s.record(job, outcome{kind: "dead", lastError: lastError})
+}
+
+type testPayload struct {
+	Value string `json:"value"`
+}
+
+func TestWorker_Outcomes(t *testing.T) {
+	payload := json.RawMessage(`{"value":"ok"}`)
+	store := newFakeStore(
+		&Job{ID: 1, Kind: "ok", Payload: payload, MaxAttempts: 3},
+		&Job{ID: 2, Kind: "fail", Payload: payload, MaxAttempts: 3},
+		&Job{ID: 3, Kind: "fail", Payload: payload, Attempts: 2, MaxAttempts: 3},
+		&Job{ID: 4, Kind: "snooze", Payload: payload, MaxAttempts: 3},
+		&Job{ID: 5, Kind: "ok", Payload: json.RawMessage(`[]`), MaxAttempts: 3},
+		&Job{ID: 6, Kind: "panic", Payload: payload, MaxAttempts: 3},
+		&Job{ID: 7, Kind: "ok", Payload: payload, Attempts: 3, MaxAttempts: 3},
+	)
+
+	w := &Worker{
+		store:          store,
+		id:             "worker-1",
+		handlers:       make(map[string]Handler),
+		concurrency:    2,
+		pollInterval:   time.Hour,
+		lease:          time.Minute,
+		retryBaseDelay: time.Minute,
+		retryMaxDelay:  time.Hour,
+		wakeCh:         make(chan struct{}, 1),
+		logger:         zerolog.Nop(),
+	}
+	w.Register("ok", Typed(func(ctx context.Context, p testPayload) error {
+		assert.Equal(t, "ok", p.Value)
+		return nil
+	}))
+	w.Register("fail", func(ctx context.Context, job *Job) error {
+		return errors.New("boom")
+	})
+	w.Register("snooze", func(ctx context.Context, job *Job) error {
+		return Snooze(time.Hour)
+	})
+	w.Register("panic", func(ctx context.Context, job *Job) error {
+		panic("oops")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Now()
+	go w.Run(ctx)
+
+	for range 7 {
+		select {
+		case <-store.done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("jobs did not finish")
+		}
+	}
+	cancel()
+	w.Stop()
+
+	assert.Equal(t, "complete", store.outcomes[1].kind)
+
+	retry := store.outcomes[2]
+	assert.Equal(t, "reschedule", retry.kind)
+	assert.True(t, retry.countAttempt)
+	assert.Equal(t, "boom", retry.lastError)
+	assert.True(t, retry.runAt.After(start))
+
+	assert.Equal(t, outcome{kind: "dead", lastError: "boom"}, store.outcomes[3])
+
+	snooze := store.outcomes[4]
+	assert.Equal(t, "reschedule", snooze.kind)
+	assert.False(t, snooze.countAttempt)
+	assert.WithinDuration(t, start.Add(time.Hour), snooze.runAt, time.Minute)
+
+	assert.Equal(t, "dead", store.outcomes[5].kind)
+	assert.Contains(t, store.outcomes[5].lastError, ErrInvalidPayload.Error())
+
+	assert.Equal(t, "reschedule", store.outcomes[6].kind)
+	assert.Contains(t, store.outcomes[6].lastError, "job panicked: oops")
+
+	assert.Equal(t, outcome{kind: "dead", lastError: "attempts exhausted"}, store.outcomes[7])
+}
+
+func TestWorker_RegisterTwice(t *testing.T) {
+	w := &Worker{handlers: make(map[string]Handler)}
+	handler := func(ctx context.Context, job *Job) error { return nil }
+
+	w.Register("kind", handler)
+	require.Panics(t, func() { w.Register("kind", handler) })
+}
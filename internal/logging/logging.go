@@ -0,0 +1,43 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// RequestIDHeader carries the request ID in both directions, an incoming one
+// is kept so a request can be followed across services.
+const RequestIDHeader = "X-Request-ID"
+
+type ctxKey int
+
+const (
+	loggerKey ctxKey = iota
+	requestIDKey
+)
+
+// WithLogger attaches a scoped logger, e.g. one carrying the request ID, to
+// ctx. Code down the call chain picks it up with FromContext.
+func WithLogger(ctx context.Context, logger zerolog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, &logger)
+}
+
+// FromContext returns the logger attached to ctx, or fallback outside of a
+// request or job.
+func FromContext(ctx context.Context, fallback *zerolog.Logger) *zerolog.Logger {
+	if logger, ok := ctx.Value(loggerKey).(*zerolog.Logger); ok {
+		return logger
+	}
+	return fallback
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the ID of the request being served, empty outside of one.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
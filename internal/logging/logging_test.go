@@ -0,0 +1,28 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext(t *testing.T) {
+	var fallbackOut, scopedOut bytes.Buffer
+	fallback := zerolog.New(&fallbackOut)
+
+	FromContext(context.Background(), &fallback).Info().Msg("outside")
+	assert.Contains(t, fallbackOut.String(), "outside")
+
+	ctx := WithLogger(context.Background(), zerolog.New(&scopedOut).With().Str("request_id", "abc").Logger())
+	FromContext(ctx, &fallback).Info().Msg("inside")
+	assert.Contains(t, scopedOut.String(), `"request_id":"abc"`)
+	assert.NotContains(t, fallbackOut.String(), "inside")
+}
+
+func TestRequestID(t *testing.T) {
+	assert.Empty(t, RequestID(context.Background()))
+	assert.Equal(t, "abc", RequestID(WithRequestID(context.Background(), "abc")))
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+)
+
+const backlogQueryTimeout = 5 * time.Second
+
+// dbPoolCollector exports pgxpool.Stat on every scrape.
+type dbPoolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns   *prometheus.Desc
+	idleConns       *prometheus.Desc
+	totalConns      *prometheus.Desc
+	maxConns        *prometheus.Desc
+	acquireCount    *prometheus.Desc
+	acquireDuration *prometheus.Desc
+	emptyAcquires   *prometheus.Desc
+	canceledAcquire *prometheus.Desc
+}
+
+func NewDBPoolCollector(pool *pgxpool.Pool) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+	return &dbPoolCollector{
+		pool:            pool,
+		acquiredConns:   desc("acquired_conns", "Connections currently in use."),
+		idleConns:       desc("idle_conns", "Idle connections in the pool."),
+		totalConns:      desc("total_conns", "Open connections in the pool."),
+		maxConns:        desc("max_conns", "Max size of the pool."),
+		acquireCount:    desc("acquires_total", "Successful connection acquires."),
+		acquireDuration: desc("acquire_duration_seconds_total", "Time spent acquiring connections."),
+		emptyAcquires:   desc("empty_acquires_total", "Acquires that had to wait for a connection."),
+		canceledAcquire: desc("canceled_acquires_total", "Acquires canceled by their context."),
+	}
+}
+
+func (c *dbPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *dbPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquire, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+}
+
+// backlogCollector exports the number of non-terminal orders by status,
+// queried on every scrape.
+type backlogCollector struct {
+	count  func(ctx context.Context) (map[string]int64, error)
+	desc   *prometheus.Desc
+	logger zerolog.Logger
+}
+
+func NewBacklogCollector(count func(ctx context.Context) (map[string]int64, error), logger zerolog.Logger) prometheus.Collector {
+	return &backlogCollector{
+		count: count,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "orders", "backlog"),
+			"Non-terminal orders by status.",
+			[]string{"status"}, nil),
+		logger: logger,
+	}
+}
+
+func (c *backlogCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *backlogCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), backlogQueryTimeout)
+	defer cancel()
+
+	counts, err := c.count(ctx)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("Failed to count order backlog")
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for status, n := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(n), status)
+	}
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBacklogCollector(t *testing.T) {
+	collector := NewBacklogCollector(func(context.Context) (map[string]int64, error) {
+		return map[string]int64{"NEW": 3, "PROCESSING": 1}, nil
+	}, zerolog.Nop())
+
+	expected := `
+		# HELP gophermart_orders_backlog Non-terminal orders by status.
+		# TYPE gophermart_orders_backlog gauge
+		gophermart_orders_backlog{status="NEW"} 3
+		gophermart_orders_backlog{status="PROCESSING"} 1
+	`
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+}
+
+func TestBacklogCollector_Error(t *testing.T) {
+	collector := NewBacklogCollector(func(context.Context) (map[string]int64, error) {
+		return nil, errors.New("db down")
+	}, zerolog.Nop())
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	_, err := registry.Gather()
+	assert.ErrorContains(t, err, "db down")
+}
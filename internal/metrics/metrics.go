@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "gophermart"
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	AccrualRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "accrual",
+		Name:      "requests_total",
+		Help:      "Requests to accrual providers by provider and status code, error for transport failures.",
+	}, []string{"provider", "status"})
+
+	AccrualRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "accrual",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of requests to accrual providers.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"provider"})
+
+	AccrualRateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "accrual",
+		Name:      "rate_limited_total",
+		Help:      "429 responses from accrual providers.",
+	}, []string{"provider"})
+
+	RateLimiterBlocked = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "accrual",
+		Name:      "rate_limiter_blocked_seconds_total",
+		Help:      "Time requests to accrual providers were blocked after a 429.",
+	}, []string{"provider"})
+
+	SyncBatchSize = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "batch_size",
+		Help:      "Orders claimed per orders sync job run.",
+		Buckets:   []float64{0, 1, 2, 5, 10, 20, 50, 100},
+	})
+
+	SyncOutcomes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "outcomes_total",
+		Help:      "Order syncs by outcome: applied, deferred, failed, dead_lettered or skipped.",
+	}, []string{"outcome"})
+
+	SyncConcurrency = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "concurrency",
+		Help:      "Orders synced concurrently by the sync pool.",
+	})
+
+	SyncQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "queue_depth",
+		Help:      "Claimed orders waiting in the sync pool queue.",
+	})
+)
+
+const (
+	SyncOutcomeApplied      = "applied"
+	SyncOutcomeDeferred     = "deferred"
+	SyncOutcomeFailed       = "failed"
+	SyncOutcomeDeadLettered = "dead_lettered"
+	SyncOutcomeSkipped      = "skipped"
+)
+
+// NewRegistry returns a registry of the Go and process collectors and the
+// service metrics. Every App serves its own registry, collectors bound to its
+// resources are added to it.
+func NewRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		AccrualRequests,
+		AccrualRequestDuration,
+		AccrualRateLimited,
+		RateLimiterBlocked,
+		SyncBatchSize,
+		SyncOutcomes,
+		SyncConcurrency,
+		SyncQueueDepth,
+	)
+	return registry
+}
+
+func Handler(gatherer prometheus.Gatherer) http.Handler {
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRegistry(t *testing.T) {
+	first := NewRegistry()
+	second := NewRegistry()
+
+	SyncOutcomes.WithLabelValues(SyncOutcomeApplied).Inc()
+
+	for _, registry := range []http.Handler{Handler(first), Handler(second)} {
+		rec := httptest.NewRecorder()
+		registry.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `gophermart_sync_outcomes_total{outcome="applied"}`)
+		assert.Contains(t, rec.Body.String(), "go_goroutines")
+	}
+}
@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/etoneja/go-gophermart/internal/httperr"
+	"github.com/gin-gonic/gin"
+)
+
+const AdminTokenHeader = "X-Admin-Token"
+
+func (m *Middlewares) AdminMiddleware(adminToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if adminToken == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, httperr.Response(c, "admin api disabled"))
+			return
+		}
+
+		token := c.GetHeader(AdminTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			m.log(c).Warn().Msg("Invalid admin token")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, httperr.Response(c, "invalid admin token"))
+			return
+		}
+
+		c.Next()
+	}
+}
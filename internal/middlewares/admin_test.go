@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		adminToken string
+		token      string
+		wantStatus int
+	}{
+		{name: "disabled", adminToken: "", token: "", wantStatus: http.StatusForbidden},
+		{name: "missing token", adminToken: "secret", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", adminToken: "secret", token: "guess", wantStatus: http.StatusUnauthorized},
+		{name: "valid token", adminToken: "secret", token: "secret", wantStatus: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMiddlewares(nil, zerolog.Nop())
+
+			router := gin.New()
+			router.Use(m.AdminMiddleware(tt.adminToken))
+			router.GET("/", func(c *gin.Context) {
+				c.Status(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.token != "" {
+				req.Header.Set(AdminTokenHeader, tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
@@ -3,6 +3,8 @@ package middlewares
 import (
 	"net/http"
 
+	"github.com/etoneja/go-gophermart/internal/httperr"
+	"github.com/etoneja/go-gophermart/internal/logging"
 	"github.com/gin-gonic/gin"
 )
 
@@ -10,26 +12,29 @@ func (m *Middlewares) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader("Authorization")
 		if tokenString == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authorization header required"})
+			c.AbortWithStatusJSON(http.StatusUnauthorized, httperr.Response(c, "authorization header required"))
 			return
 		}
 
 		login, err := m.svc.ValidateToken(tokenString)
 		if err != nil {
-			m.logger.Debug().Err(err).Msg("Invalid token")
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			m.log(c).Debug().Err(err).Msg("Invalid token")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, httperr.Response(c, "invalid token"))
 			return
 		}
 
-		user, err := m.svc.GetUserByLogin(c, login)
+		user, err := m.svc.GetUserByLogin(c.Request.Context(), login)
 		if err != nil {
-			m.logger.Warn().Err(err).Msg("Can't fetch user")
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "can't fetch user"})
+			m.log(c).Warn().Err(err).Msg("Can't fetch user")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, httperr.Response(c, "can't fetch user"))
 			return
 		}
 
 		c.Set("user", user)
 
+		logger := m.log(c).With().Str("user_uuid", user.UUID).Logger()
+		c.Request = c.Request.WithContext(logging.WithLogger(c.Request.Context(), logger))
+
 		c.Next()
 	}
 }
@@ -1,7 +1,9 @@
 package middlewares
 
 import (
+	"github.com/etoneja/go-gophermart/internal/logging"
 	"github.com/etoneja/go-gophermart/internal/service"
+	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
 )
 
@@ -16,3 +18,8 @@ func NewMiddlewares(svc service.Servicer, logger zerolog.Logger) *Middlewares {
 		logger: logger,
 	}
 }
+
+// log returns the request-scoped logger set up by RequestIDMiddleware.
+func (m *Middlewares) log(c *gin.Context) *zerolog.Logger {
+	return logging.FromContext(c.Request.Context(), &m.logger)
+}
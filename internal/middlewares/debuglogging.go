@@ -22,11 +22,14 @@ func (m *Middlewares) DebugLoggingMiddleware() gin.HandlerFunc {
 		start := time.Now()
 		c.Next()
 
-		logEvent := m.logger.Info().
+		duration := time.Since(start)
+		observeRequest(c, duration)
+
+		logEvent := m.log(c).Info().
 			Str("method", c.Request.Method).
 			Str("path", c.Request.URL.Path).
 			Int("status", c.Writer.Status()).
-			Dur("duration", time.Since(start))
+			Dur("duration", duration)
 
 		if len(requestBody) > 0 {
 			logEvent = logEvent.RawJSON("request_body", requestBody)
@@ -1,8 +1,10 @@
 package middlewares
 
 import (
+	"strconv"
 	"time"
 
+	"github.com/etoneja/go-gophermart/internal/metrics"
 	"github.com/gin-gonic/gin"
 )
 
@@ -12,11 +14,27 @@ func (m *Middlewares) LoggingMiddleware() gin.HandlerFunc {
 
 		c.Next()
 
-		m.logger.Info().
+		duration := time.Since(start)
+		observeRequest(c, duration)
+
+		m.log(c).Info().
 			Str("method", c.Request.Method).
 			Str("path", c.Request.URL.Path).
 			Int("status", c.Writer.Status()).
-			Dur("duration", time.Since(start)).
+			Dur("duration", duration).
 			Msg("HTTP request")
 	}
 }
+
+// observeRequest records the request in the HTTP metrics, labelled by the
+// route template rather than the path to keep the number of series bounded.
+func observeRequest(c *gin.Context, duration time.Duration) {
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	status := strconv.Itoa(c.Writer.Status())
+
+	metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+	metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, route, status).Observe(duration.Seconds())
+}
@@ -0,0 +1,50 @@
+package middlewares
+
+import (
+	"github.com/etoneja/go-gophermart/internal/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware keeps the caller's X-Request-ID or generates one, echoes
+// it in the response and attaches a logger carrying it to the request
+// context, so service and repository logs of the request can be tied
+// together.
+func (m *Middlewares) RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(logging.RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Header(logging.RequestIDHeader, requestID)
+
+		ctx := c.Request.Context()
+		logCtx := m.logger.With().Str("request_id", requestID)
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+			logCtx = logCtx.Str("trace_id", span.TraceID().String())
+		}
+
+		ctx = logging.WithRequestID(ctx, requestID)
+		ctx = logging.WithLogger(ctx, logCtx.Logger())
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// validRequestID accepts short printable ASCII IDs, anything else could
+// forge log lines or blow up their size.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < 0x21 || requestID[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package middlewares
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/etoneja/go-gophermart/internal/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		wantKept bool
+	}{
+		{name: "generated", incoming: ""},
+		{name: "valid incoming", incoming: "client-req-42", wantKept: true},
+		{name: "control characters", incoming: "forged\nline"},
+		{name: "non-ASCII", incoming: "запрос"},
+		{name: "too long", incoming: strings.Repeat("a", maxRequestIDLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			m := NewMiddlewares(nil, zerolog.New(&logs))
+
+			var ctxRequestID string
+			router := gin.New()
+			router.Use(m.RequestIDMiddleware())
+			router.GET("/", func(c *gin.Context) {
+				ctxRequestID = logging.RequestID(c.Request.Context())
+				m.log(c).Info().Msg("handled")
+				c.Status(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(logging.RequestIDHeader, tt.incoming)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			requestID := w.Header().Get(logging.RequestIDHeader)
+			if tt.wantKept {
+				assert.Equal(t, tt.incoming, requestID)
+			} else {
+				_, err := uuid.Parse(requestID)
+				require.NoError(t, err, "a fresh UUID replaces a missing or invalid ID")
+			}
+			assert.Equal(t, requestID, ctxRequestID)
+			assert.Contains(t, logs.String(), `"request_id":"`+requestID+`"`)
+		})
+	}
+}
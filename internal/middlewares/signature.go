@@ -0,0 +1,57 @@
+package middlewares
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/httperr"
+	"github.com/etoneja/go-gophermart/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	SignatureHeader          = utils.SignatureHeader
+	SignatureTimestampHeader = utils.SignatureTimestampHeader
+
+	maxSignedBodySize = 1 << 20
+)
+
+// SignatureMiddleware accepts only requests whose body is signed with secret
+// (see utils.SignPayload) and whose timestamp is within maxSkew of now.
+func (m *Middlewares) SignatureMiddleware(secret string, maxSkew time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if secret == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, httperr.Response(c, "callbacks disabled"))
+			return
+		}
+
+		timestamp := c.GetHeader(SignatureTimestampHeader)
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, httperr.Response(c, "invalid signature timestamp"))
+			return
+		}
+		if skew := time.Since(time.Unix(unix, 0)); skew > maxSkew || skew < -maxSkew {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, httperr.Response(c, "signature expired"))
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxSignedBodySize))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, httperr.Response(c, "can't read body"))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if !utils.VerifySignature(secret, timestamp, body, c.GetHeader(SignatureHeader)) {
+			m.log(c).Warn().Msg("Invalid request signature")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, httperr.Response(c, "invalid signature"))
+			return
+		}
+
+		c.Next()
+	}
+}
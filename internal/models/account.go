@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+type AccountModel struct {
+	UserID      string    `json:"-"`
+	ProgramCode string    `json:"-"`
+	Balance     int64     `json:"-"`
+	CreatedAt   time.Time `json:"-"`
+}
+
+func NewAccount(userID, programCode string) *AccountModel {
+	return &AccountModel{
+		UserID:      userID,
+		ProgramCode: programCode,
+		Balance:     0,
+		CreatedAt:   time.Now(),
+	}
+}
@@ -1,18 +1,39 @@
 package models
 
 type BalanceModel struct {
-	Current   int64 `json:"-"`
-	Withdrawn int64 `json:"-"`
+	ProgramCode string `json:"-"`
+	Current     int64  `json:"-"`
+	Withdrawn   int64  `json:"-"`
 }
 
 func (b *BalanceModel) ToResponse() BalanceResponse {
 	return BalanceResponse{
-		Current:   KopecksToRubles(b.Current),
-		Withdrawn: KopecksToRubles(b.Withdrawn),
+		Current:   Money(b.Current),
+		Withdrawn: Money(b.Withdrawn),
 	}
 }
 
 type BalanceResponse struct {
-	Current   float64 `json:"current"`
-	Withdrawn float64 `json:"withdrawn"`
+	Current   Money `json:"current"`
+	Withdrawn Money `json:"withdrawn"`
+}
+
+type AccountBalanceResponse struct {
+	Program   string `json:"program"`
+	Current   Money  `json:"current"`
+	Withdrawn Money  `json:"withdrawn"`
+}
+
+type BalanceModelList []*BalanceModel
+
+func (list BalanceModelList) ToResponse() []*AccountBalanceResponse {
+	resp := make([]*AccountBalanceResponse, len(list))
+	for i, item := range list {
+		resp[i] = &AccountBalanceResponse{
+			Program:   item.ProgramCode,
+			Current:   Money(item.Current),
+			Withdrawn: Money(item.Withdrawn),
+		}
+	}
+	return resp
 }
@@ -0,0 +1,214 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+type CampaignBonusType string
+
+const (
+	// CampaignBonusTypeFixed credits a fixed amount per eligible order.
+	CampaignBonusTypeFixed CampaignBonusType = "fixed"
+	// CampaignBonusTypePercent credits a percentage of the order accrual.
+	CampaignBonusTypePercent CampaignBonusType = "percent"
+)
+
+// CampaignEligibility holds the predicates an order must satisfy for the
+// campaign to apply. Empty predicates match every order. Weekdays are
+// evaluated in UTC, so eligibility doesn't depend on the server's time zone.
+type CampaignEligibility struct {
+	FirstOrderOnly bool           `json:"first_order_only,omitempty"`
+	MinAccrual     *Money         `json:"min_accrual,omitempty"`
+	Weekdays       []time.Weekday `json:"weekdays,omitempty"`
+	Tiers          []TierLevel    `json:"tiers,omitempty"`
+}
+
+// MaxCampaignBonusPercent bounds a percent bonus to ten times the order
+// accrual, so a typo in a campaign can't credit an unbounded amount.
+const MaxCampaignBonusPercent = 1000
+
+type CampaignBonus struct {
+	Type    CampaignBonusType `json:"type"`
+	Amount  Money             `json:"amount,omitempty"`
+	Percent int64             `json:"percent,omitempty"`
+}
+
+func (b CampaignBonus) Validate() error {
+	switch b.Type {
+	case CampaignBonusTypeFixed:
+		if b.Amount <= 0 {
+			return errors.New("fixed bonus amount should be positive")
+		}
+	case CampaignBonusTypePercent:
+		if b.Percent <= 0 {
+			return errors.New("bonus percent should be positive")
+		}
+		if b.Percent > MaxCampaignBonusPercent {
+			return fmt.Errorf("bonus percent should not exceed %d", MaxCampaignBonusPercent)
+		}
+	default:
+		return errors.New("unknown bonus type")
+	}
+	return nil
+}
+
+// CampaignEvalContext describes a credited order a campaign is evaluated against.
+type CampaignEvalContext struct {
+	Accrual     int64
+	IsFirst     bool
+	Tier        TierLevel
+	ProcessedAt time.Time
+}
+
+type CampaignModel struct {
+	UUID        string              `json:"-"`
+	Name        string              `json:"-"`
+	ProgramCode string              `json:"-"`
+	StartsAt    time.Time           `json:"-"`
+	EndsAt      *time.Time          `json:"-"`
+	Active      bool                `json:"-"`
+	Eligibility CampaignEligibility `json:"-"`
+	Bonus       CampaignBonus       `json:"-"`
+	CreatedAt   time.Time           `json:"-"`
+	UpdatedAt   time.Time           `json:"-"`
+}
+
+func (c *CampaignModel) IsRunning(at time.Time) bool {
+	if !c.Active || at.Before(c.StartsAt) {
+		return false
+	}
+	return c.EndsAt == nil || at.Before(*c.EndsAt)
+}
+
+func (c *CampaignModel) IsEligible(ec CampaignEvalContext) bool {
+	if !c.IsRunning(ec.ProcessedAt) {
+		return false
+	}
+
+	e := c.Eligibility
+	if e.FirstOrderOnly && !ec.IsFirst {
+		return false
+	}
+	if e.MinAccrual != nil && ec.Accrual < e.MinAccrual.Kopecks() {
+		return false
+	}
+	if len(e.Weekdays) > 0 && !slices.Contains(e.Weekdays, ec.ProcessedAt.UTC().Weekday()) {
+		return false
+	}
+	if len(e.Tiers) > 0 && !slices.Contains(e.Tiers, ec.Tier) {
+		return false
+	}
+	return true
+}
+
+// BonusFor returns the bonus amount in kopecks for an eligible order.
+func (c *CampaignModel) BonusFor(ec CampaignEvalContext) int64 {
+	switch c.Bonus.Type {
+	case CampaignBonusTypeFixed:
+		return c.Bonus.Amount.Kopecks()
+	case CampaignBonusTypePercent:
+		return percentOf(ec.Accrual, c.Bonus.Percent)
+	}
+	return 0
+}
+
+// percentOf returns amount*percent/100, clamped to math.MaxInt64 instead of
+// overflowing for large accruals.
+func percentOf(amount, percent int64) int64 {
+	if amount <= 0 || percent <= 0 {
+		return 0
+	}
+	if amount <= math.MaxInt64/percent {
+		return amount * percent / 100
+	}
+
+	whole, rest := amount/100, amount%100*percent/100
+	if whole > (math.MaxInt64-rest)/percent {
+		return math.MaxInt64
+	}
+	return whole*percent + rest
+}
+
+type CampaignRequest struct {
+	Name        string              `json:"name" binding:"required,max=255"`
+	Program     string              `json:"program"`
+	StartsAt    time.Time           `json:"starts_at" binding:"required"`
+	EndsAt      *time.Time          `json:"ends_at"`
+	Active      *bool               `json:"active"`
+	Eligibility CampaignEligibility `json:"eligibility"`
+	Bonus       CampaignBonus       `json:"bonus"`
+}
+
+func (req *CampaignRequest) Validate() error {
+	if req.EndsAt != nil && !req.EndsAt.After(req.StartsAt) {
+		return errors.New("ends_at should be after starts_at")
+	}
+	return req.Bonus.Validate()
+}
+
+func (req *CampaignRequest) ToModel() *CampaignModel {
+	programCode := req.Program
+	if programCode == "" {
+		programCode = DefaultProgramCode
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	now := time.Now()
+	return &CampaignModel{
+		Name:        req.Name,
+		ProgramCode: programCode,
+		StartsAt:    req.StartsAt,
+		EndsAt:      req.EndsAt,
+		Active:      active,
+		Eligibility: req.Eligibility,
+		Bonus:       req.Bonus,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+type CampaignResponse struct {
+	ID          string              `json:"id"`
+	Name        string              `json:"name"`
+	Program     string              `json:"program"`
+	StartsAt    time.Time           `json:"starts_at"`
+	EndsAt      *time.Time          `json:"ends_at,omitempty"`
+	Active      bool                `json:"active"`
+	Eligibility CampaignEligibility `json:"eligibility"`
+	Bonus       CampaignBonus       `json:"bonus"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+func (c *CampaignModel) ToResponse() *CampaignResponse {
+	return &CampaignResponse{
+		ID:          c.UUID,
+		Name:        c.Name,
+		Program:     c.ProgramCode,
+		StartsAt:    c.StartsAt,
+		EndsAt:      c.EndsAt,
+		Active:      c.Active,
+		Eligibility: c.Eligibility,
+		Bonus:       c.Bonus,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+}
+
+type CampaignModelList []*CampaignModel
+
+func (list CampaignModelList) ToResponse() []*CampaignResponse {
+	resp := make([]*CampaignResponse, len(list))
+	for i, item := range list {
+		resp[i] = item.ToResponse()
+	}
+	return resp
+}
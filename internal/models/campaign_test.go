@@ -0,0 +1,142 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCampaignModel_IsEligible(t *testing.T) {
+	saturday := time.Date(2025, time.June, 7, 12, 0, 0, 0, time.UTC)
+	minAccrual := Money(10000)
+
+	tests := []struct {
+		name     string
+		campaign CampaignModel
+		evalCtx  CampaignEvalContext
+		want     bool
+	}{
+		{
+			name:     "no predicates",
+			campaign: CampaignModel{Active: true, StartsAt: saturday.Add(-time.Hour)},
+			evalCtx:  CampaignEvalContext{ProcessedAt: saturday},
+			want:     true,
+		},
+		{
+			name:     "inactive",
+			campaign: CampaignModel{Active: false, StartsAt: saturday.Add(-time.Hour)},
+			evalCtx:  CampaignEvalContext{ProcessedAt: saturday},
+			want:     false,
+		},
+		{
+			name: "ended",
+			campaign: CampaignModel{
+				Active:   true,
+				StartsAt: saturday.Add(-2 * time.Hour),
+				EndsAt:   func() *time.Time { t := saturday.Add(-time.Hour); return &t }(),
+			},
+			evalCtx: CampaignEvalContext{ProcessedAt: saturday},
+			want:    false,
+		},
+		{
+			name: "first order only",
+			campaign: CampaignModel{
+				Active:      true,
+				StartsAt:    saturday.Add(-time.Hour),
+				Eligibility: CampaignEligibility{FirstOrderOnly: true},
+			},
+			evalCtx: CampaignEvalContext{ProcessedAt: saturday, IsFirst: false},
+			want:    false,
+		},
+		{
+			name: "weekend",
+			campaign: CampaignModel{
+				Active:      true,
+				StartsAt:    saturday.Add(-time.Hour),
+				Eligibility: CampaignEligibility{Weekdays: []time.Weekday{time.Saturday, time.Sunday}},
+			},
+			evalCtx: CampaignEvalContext{ProcessedAt: saturday},
+			want:    true,
+		},
+		{
+			name: "weekday in UTC",
+			campaign: CampaignModel{
+				Active:      true,
+				StartsAt:    saturday.Add(-time.Hour),
+				Eligibility: CampaignEligibility{Weekdays: []time.Weekday{time.Saturday}},
+			},
+			// Still Saturday in UTC, already Sunday in Tokyo.
+			evalCtx: CampaignEvalContext{ProcessedAt: saturday.Add(11 * time.Hour).In(time.FixedZone("JST", 9*60*60))},
+			want:    true,
+		},
+		{
+			name: "below min accrual",
+			campaign: CampaignModel{
+				Active:      true,
+				StartsAt:    saturday.Add(-time.Hour),
+				Eligibility: CampaignEligibility{MinAccrual: &minAccrual},
+			},
+			evalCtx: CampaignEvalContext{ProcessedAt: saturday, Accrual: 9999},
+			want:    false,
+		},
+		{
+			name: "tier mismatch",
+			campaign: CampaignModel{
+				Active:      true,
+				StartsAt:    saturday.Add(-time.Hour),
+				Eligibility: CampaignEligibility{Tiers: []TierLevel{TierLevelGold}},
+			},
+			evalCtx: CampaignEvalContext{ProcessedAt: saturday, Tier: TierLevelSilver},
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.campaign.IsEligible(tt.evalCtx))
+		})
+	}
+}
+
+func TestCampaignModel_BonusFor(t *testing.T) {
+	fixed := CampaignModel{Bonus: CampaignBonus{Type: CampaignBonusTypeFixed, Amount: 50000}}
+	assert.Equal(t, int64(50000), fixed.BonusFor(CampaignEvalContext{Accrual: 100}))
+
+	double := CampaignModel{Bonus: CampaignBonus{Type: CampaignBonusTypePercent, Percent: 100}}
+	assert.Equal(t, int64(12345), double.BonusFor(CampaignEvalContext{Accrual: 12345}))
+
+	half := CampaignModel{Bonus: CampaignBonus{Type: CampaignBonusTypePercent, Percent: 50}}
+	assert.Equal(t, int64(math.MaxInt64/10/2), half.BonusFor(CampaignEvalContext{Accrual: math.MaxInt64 / 10}))
+
+	triple := CampaignModel{Bonus: CampaignBonus{Type: CampaignBonusTypePercent, Percent: 300}}
+	assert.Equal(t, int64(math.MaxInt64), triple.BonusFor(CampaignEvalContext{Accrual: math.MaxInt64 / 2}))
+}
+
+func TestCampaignRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		bonus   CampaignBonus
+		wantErr bool
+	}{
+		{name: "fixed", bonus: CampaignBonus{Type: CampaignBonusTypeFixed, Amount: 100}},
+		{name: "fixed not positive", bonus: CampaignBonus{Type: CampaignBonusTypeFixed}, wantErr: true},
+		{name: "percent", bonus: CampaignBonus{Type: CampaignBonusTypePercent, Percent: 50}},
+		{name: "percent at limit", bonus: CampaignBonus{Type: CampaignBonusTypePercent, Percent: MaxCampaignBonusPercent}},
+		{name: "percent over limit", bonus: CampaignBonus{Type: CampaignBonusTypePercent, Percent: MaxCampaignBonusPercent + 1}, wantErr: true},
+		{name: "percent not positive", bonus: CampaignBonus{Type: CampaignBonusTypePercent}, wantErr: true},
+		{name: "unknown type", bonus: CampaignBonus{Type: "gift"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &CampaignRequest{StartsAt: time.Now(), Bonus: tt.bonus}
+			if tt.wantErr {
+				assert.Error(t, req.Validate())
+			} else {
+				assert.NoError(t, req.Validate())
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// ConversionModel moves points of one program of a user to another program
+// of the same user at the programs' conversion rates.
+type ConversionModel struct {
+	UserID          string    `json:"-"`
+	FromProgramCode string    `json:"-"`
+	ToProgramCode   string    `json:"-"`
+	Amount          int64     `json:"-"`
+	Converted       int64     `json:"-"`
+	CreatedAt       time.Time `json:"-"`
+}
+
+type ConversionRequest struct {
+	From string `json:"from"`
+	To   string `json:"to" binding:"required"`
+	Sum  Money  `json:"sum" binding:"required,gt=0"`
+}
+
+func (req *ConversionRequest) ToModel(userID string) *ConversionModel {
+	fromProgramCode := req.From
+	if fromProgramCode == "" {
+		fromProgramCode = DefaultProgramCode
+	}
+
+	return &ConversionModel{
+		UserID:          userID,
+		FromProgramCode: fromProgramCode,
+		ToProgramCode:   req.To,
+		Amount:          req.Sum.Kopecks(),
+		CreatedAt:       time.Now(),
+	}
+}
+
+type ConversionResponse struct {
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	Sum         Money     `json:"sum"`
+	Converted   Money     `json:"converted"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+func (c *ConversionModel) ToResponse() *ConversionResponse {
+	return &ConversionResponse{
+		From:        c.FromProgramCode,
+		To:          c.ToProgramCode,
+		Sum:         Money(c.Amount),
+		Converted:   Money(c.Converted),
+		ProcessedAt: c.CreatedAt,
+	}
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const moneyScale = 100
+
+var (
+	ErrMoneyFormat    = errors.New("money: invalid format")
+	ErrMoneyPrecision = errors.New("money: more than two decimal places")
+	ErrMoneyNegative  = errors.New("money: negative value")
+	ErrMoneyOverflow  = errors.New("money: value out of range")
+)
+
+// Money is a fixed-point amount stored in kopecks and encoded in JSON as a
+// decimal number with at most two fractional digits.
+type Money int64
+
+func (m Money) Kopecks() int64 {
+	return int64(m)
+}
+
+func (m Money) String() string {
+	sign := ""
+	v := uint64(m)
+	if m < 0 {
+		sign = "-"
+		v = uint64(-m)
+	}
+
+	whole := v / moneyScale
+	frac := v % moneyScale
+
+	switch {
+	case frac == 0:
+		return fmt.Sprintf("%s%d", sign, whole)
+	case frac%10 == 0:
+		return fmt.Sprintf("%s%d.%d", sign, whole, frac/10)
+	default:
+		return fmt.Sprintf("%s%d.%02d", sign, whole, frac)
+	}
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+
+	v, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+
+	*m = v
+	return nil
+}
+
+// ParseMoney parses a non-negative decimal such as "729.98" into kopecks.
+// Exponent notation, signs and more than two fractional digits are rejected.
+func ParseMoney(s string) (Money, error) {
+	if s == "" {
+		return 0, ErrMoneyFormat
+	}
+	if s[0] == '-' {
+		return 0, ErrMoneyNegative
+	}
+
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if !isDigits(whole) || (hasFrac && !isDigits(frac)) {
+		return 0, ErrMoneyFormat
+	}
+	if len(whole) > 1 && whole[0] == '0' {
+		return 0, ErrMoneyFormat
+	}
+	if len(frac) > 2 {
+		return 0, ErrMoneyPrecision
+	}
+
+	wholeValue, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, ErrMoneyOverflow
+	}
+	if wholeValue > math.MaxInt64/moneyScale {
+		return 0, ErrMoneyOverflow
+	}
+
+	var fracValue int64
+	if frac != "" {
+		fracValue, _ = strconv.ParseInt(frac, 10, 64)
+		if len(frac) == 1 {
+			fracValue *= 10
+		}
+	}
+
+	kopecks := wholeValue*moneyScale + fracValue
+	if kopecks < 0 {
+		return 0, ErrMoneyOverflow
+	}
+
+	return Money(kopecks), nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoney_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Money
+		wantErr error
+	}{
+		{name: "integer", input: `500`, want: 50000},
+		{name: "one decimal", input: `100.5`, want: 10050},
+		{name: "two decimals", input: `729.98`, want: 72998},
+		{name: "small fraction", input: `0.01`, want: 1},
+		{name: "zero", input: `0`, want: 0},
+		{name: "max", input: `92233720368547758.07`, want: 9223372036854775807},
+		{name: "three decimals", input: `0.015`, wantErr: ErrMoneyPrecision},
+		{name: "negative", input: `-1`, wantErr: ErrMoneyNegative},
+		{name: "overflow fraction", input: `92233720368547758.08`, wantErr: ErrMoneyOverflow},
+		{name: "overflow whole", input: `100000000000000000000`, wantErr: ErrMoneyOverflow},
+		{name: "exponent", input: `1e2`, wantErr: ErrMoneyFormat},
+		{name: "string", input: `"1.00"`, wantErr: ErrMoneyFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Money
+			err := json.Unmarshal([]byte(tt.input), &m)
+
+			if tt.wantErr != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, m)
+		})
+	}
+}
+
+func TestParseMoney_InvalidFormat(t *testing.T) {
+	for _, input := range []string{``, `01`, `1.`, `.5`, `1.2.3`, `+1`, `1,5`, ` 1`} {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseMoney(input)
+			assert.ErrorIs(t, err, ErrMoneyFormat)
+		})
+	}
+}
+
+func TestMoney_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		input Money
+		want  string
+	}{
+		{input: 0, want: `0`},
+		{input: 50000, want: `500`},
+		{input: 10050, want: `100.5`},
+		{input: 72998, want: `729.98`},
+		{input: 1, want: `0.01`},
+		{input: -150, want: `-1.5`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got, err := json.Marshal(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func FuzzMoney_UnmarshalJSON(f *testing.F) {
+	for _, seed := range []string{`0`, `1`, `0.01`, `100.5`, `729.98`, `0.015`, `-1`, `1e2`, `92233720368547758.07`} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		var m Money
+		if err := json.Unmarshal([]byte(input), &m); err != nil {
+			return
+		}
+
+		if m < 0 {
+			t.Fatalf("parsed %q into negative value %d", input, m)
+		}
+
+		encoded, err := json.Marshal(m)
+		require.NoError(t, err)
+
+		var decoded Money
+		require.NoError(t, json.Unmarshal(encoded, &decoded))
+		assert.Equal(t, m, decoded)
+	})
+}
+
+func FuzzMoney_MarshalJSON(f *testing.F) {
+	for _, seed := range []int64{0, 1, 10, 99, 100, 10050, 9223372036854775807} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, kopecks int64) {
+		if kopecks < 0 {
+			return
+		}
+
+		encoded, err := json.Marshal(Money(kopecks))
+		require.NoError(t, err)
+
+		var decoded Money
+		require.NoError(t, json.Unmarshal(encoded, &decoded))
+		assert.Equal(t, Money(kopecks), decoded)
+	})
+}
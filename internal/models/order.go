@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"slices"
 	"time"
+
+	"github.com/etoneja/go-gophermart/internal/utils"
 )
 
 type OrderStatus string
@@ -30,7 +32,7 @@ const (
 
 var AccrualOrderStatusToOrderStatus = map[AccrualOrderStatus]OrderStatus{
 	AccrualOrderStatusRegistered: OrderStatusNew,
-	AccrualOrderStatusProcessing: OrderStatusInvalid,
+	AccrualOrderStatusProcessing: OrderStatusProcessing,
 	AccrualOrderStatusInvalid:    OrderStatusInvalid,
 	AccrualOrderStatusProcessed:  OrderStatusProcessed,
 }
@@ -42,23 +44,124 @@ func ConvertAccrualOrderStatusToOrderStatus(status AccrualOrderStatus) (OrderSta
 	return "", fmt.Errorf("unknown accrual order status: %v", status)
 }
 
+// SyncLane groups due orders by how urgently they should be synced.
+type SyncLane string
+
+const (
+	// SyncLaneFresh holds recently uploaded orders the accrual system hasn't
+	// started processing, so new users get their first accrual quickly.
+	SyncLaneFresh SyncLane = "fresh"
+	// SyncLaneRecheck holds recent orders in PROCESSING due for a recheck.
+	SyncLaneRecheck SyncLane = "recheck"
+	// SyncLaneStale holds orders not terminated long after upload.
+	SyncLaneStale SyncLane = "stale"
+)
+
+// SyncLanes lists the lanes by priority.
+var SyncLanes = []SyncLane{
+	SyncLaneFresh,
+	SyncLaneRecheck,
+	SyncLaneStale,
+}
+
 type OrderModel struct {
-	ID        string      `json:"-"`
-	UserID    string      `json:"-"`
-	Status    OrderStatus `json:"-"`
-	Accrual   *int64      `json:"-"`
-	CreatedAt time.Time   `json:"-"`
-	UpdatedAt time.Time   `json:"-"`
+	ID             string      `json:"-"`
+	UserID         string      `json:"-"`
+	ProgramCode    string      `json:"-"`
+	Status         OrderStatus `json:"-"`
+	Accrual        *int64      `json:"-"`
+	SyncAttempts   int         `json:"-"`
+	NextSyncAt     *time.Time  `json:"-"`
+	LastSyncError  *string     `json:"-"`
+	DeadLetteredAt *time.Time  `json:"-"`
+	ClaimedBy      *string     `json:"-"`
+	ClaimedUntil   *time.Time  `json:"-"`
+	CreatedAt      time.Time   `json:"-"`
+	UpdatedAt      time.Time   `json:"-"`
 }
 
 func (o *OrderModel) IsTerminated() bool {
 	return slices.Contains(TerminatedOrderStatuses, o.Status)
 }
 
+// SyncLane returns the lane of the order, it becomes stale once uploaded
+// before staleBefore.
+func (o *OrderModel) SyncLane(staleBefore time.Time) SyncLane {
+	switch {
+	case !o.CreatedAt.After(staleBefore):
+		return SyncLaneStale
+	case o.Status == OrderStatusNew:
+		return SyncLaneFresh
+	default:
+		return SyncLaneRecheck
+	}
+}
+
+func (o *OrderModel) IsDeadLettered() bool {
+	return o.DeadLetteredAt != nil
+}
+
+// IsClaimedBy reports whether the worker still holds the sync lease on the
+// order, another worker claiming it after the lease expired takes it over.
+func (o *OrderModel) IsClaimedBy(workerID string) bool {
+	return o.ClaimedBy != nil && *o.ClaimedBy == workerID
+}
+
+func (o *OrderModel) ReleaseClaim() {
+	o.ClaimedBy = nil
+	o.ClaimedUntil = nil
+}
+
+// MarkSyncSucceeded clears the retry state after a successful accrual lookup.
+func (o *OrderModel) MarkSyncSucceeded() {
+	o.SyncAttempts = 0
+	o.NextSyncAt = nil
+	o.LastSyncError = nil
+}
+
+// MarkSyncFailed records a failed sync attempt and either schedules the next
+// one or dead-letters the order once maxAttempts is reached.
+func (o *OrderModel) MarkSyncFailed(syncErr error, now time.Time, backoff time.Duration, maxAttempts int) {
+	msg := syncErr.Error()
+	o.SyncAttempts++
+	o.LastSyncError = &msg
+	o.UpdatedAt = now
+
+	if o.SyncAttempts >= maxAttempts {
+		o.NextSyncAt = nil
+		o.DeadLetteredAt = &now
+		return
+	}
+
+	next := now.Add(backoff)
+	o.NextSyncAt = &next
+}
+
+// ScheduleSync postpones the next sync without counting it as a failure.
+func (o *OrderModel) ScheduleSync(at time.Time, now time.Time) {
+	o.MarkSyncSucceeded()
+	o.NextSyncAt = &at
+	o.UpdatedAt = now
+}
+
+// DeferSync postpones the next sync of an order the accrual system couldn't
+// be asked about, keeping its retry state.
+func (o *OrderModel) DeferSync(at time.Time, now time.Time) {
+	o.NextSyncAt = &at
+	o.UpdatedAt = now
+}
+
+// ResetSync makes a dead-lettered order eligible for syncing again.
+func (o *OrderModel) ResetSync(now time.Time) {
+	o.MarkSyncSucceeded()
+	o.DeadLetteredAt = nil
+	o.UpdatedAt = now
+}
+
 type OrderResponse struct {
 	Number     string      `json:"number"`
 	Status     OrderStatus `json:"status"`
-	Accrual    *float64    `json:"accrual,omitempty"`
+	Accrual    *Money      `json:"accrual,omitempty"`
 	UploadedAt time.Time   `json:"uploaded_at"`
 }
 
@@ -69,7 +172,7 @@ func (o *OrderModel) ToResponse() *OrderResponse {
 		UploadedAt: o.CreatedAt,
 	}
 	if o.Accrual != nil {
-		val := KopecksToRubles(*o.Accrual)
+		val := Money(*o.Accrual)
 		resp.Accrual = &val
 	}
 	return resp
@@ -84,7 +187,26 @@ type AccrualOrderModel struct {
 type AccrualOrderResponse struct {
 	Order   string             `json:"order"`
 	Status  AccrualOrderStatus `json:"status"`
-	Accrual *float64           `json:"accrual,omitempty"`
+	Accrual *Money             `json:"accrual,omitempty"`
+}
+
+// Validate checks an order status pushed by the accrual system.
+func (ao *AccrualOrderResponse) Validate() error {
+	if valid, err := utils.LuhnCheck(ao.Order); err != nil || !valid {
+		return fmt.Errorf("invalid order number")
+	}
+	if _, err := ConvertAccrualOrderStatusToOrderStatus(ao.Status); err != nil {
+		return err
+	}
+	if ao.Accrual != nil {
+		if ao.Status != AccrualOrderStatusProcessed {
+			return fmt.Errorf("accrual is only allowed for %s orders", AccrualOrderStatusProcessed)
+		}
+		if *ao.Accrual < 0 {
+			return fmt.Errorf("accrual must not be negative")
+		}
+	}
+	return nil
 }
 
 func (ao *AccrualOrderResponse) ToModel() *AccrualOrderModel {
@@ -93,12 +215,40 @@ func (ao *AccrualOrderResponse) ToModel() *AccrualOrderModel {
 		Status: ao.Status,
 	}
 	if ao.Accrual != nil {
-		val := RublesToKopecks(*ao.Accrual)
+		val := ao.Accrual.Kopecks()
 		model.Accrual = &val
 	}
 	return model
 }
 
+type AdminOrderResponse struct {
+	Number         string      `json:"number"`
+	UserID         string      `json:"user_id"`
+	Program        string      `json:"program"`
+	Status         OrderStatus `json:"status"`
+	SyncAttempts   int         `json:"sync_attempts"`
+	LastSyncError  *string     `json:"last_sync_error,omitempty"`
+	NextSyncAt     *time.Time  `json:"next_sync_at,omitempty"`
+	DeadLetteredAt *time.Time  `json:"dead_lettered_at,omitempty"`
+	UploadedAt     time.Time   `json:"uploaded_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+func (o *OrderModel) ToAdminResponse() *AdminOrderResponse {
+	return &AdminOrderResponse{
+		Number:         o.ID,
+		UserID:         o.UserID,
+		Program:        o.ProgramCode,
+		Status:         o.Status,
+		SyncAttempts:   o.SyncAttempts,
+		LastSyncError:  o.LastSyncError,
+		NextSyncAt:     o.NextSyncAt,
+		DeadLetteredAt: o.DeadLetteredAt,
+		UploadedAt:     o.CreatedAt,
+		UpdatedAt:      o.UpdatedAt,
+	}
+}
+
 type OrderModelList []*OrderModel
 
 func (list OrderModelList) ToResponse() []*OrderResponse {
@@ -108,3 +258,11 @@ func (list OrderModelList) ToResponse() []*OrderResponse {
 	}
 	return resp
 }
+
+func (list OrderModelList) ToAdminResponse() []*AdminOrderResponse {
+	resp := make([]*AdminOrderResponse, len(list))
+	for i, item := range list {
+		resp[i] = item.ToAdminResponse()
+	}
+	return resp
+}
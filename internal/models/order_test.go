@@ -0,0 +1,68 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderModel_SyncLane(t *testing.T) {
+	staleBefore := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		status    OrderStatus
+		createdAt time.Time
+		want      SyncLane
+	}{
+		{name: "fresh upload", status: OrderStatusNew, createdAt: time.Now(), want: SyncLaneFresh},
+		{name: "processing recheck", status: OrderStatusProcessing, createdAt: time.Now(), want: SyncLaneRecheck},
+		{name: "stale new", status: OrderStatusNew, createdAt: staleBefore.Add(-time.Minute), want: SyncLaneStale},
+		{name: "stale processing", status: OrderStatusProcessing, createdAt: staleBefore, want: SyncLaneStale},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &OrderModel{Status: tt.status, CreatedAt: tt.createdAt}
+			assert.Equal(t, tt.want, order.SyncLane(staleBefore))
+		})
+	}
+}
+
+func TestOrderModel_DeferSync(t *testing.T) {
+	now := time.Now()
+	msg := "accrual system unavailable"
+	order := &OrderModel{SyncAttempts: 2, LastSyncError: &msg}
+
+	order.DeferSync(now.Add(time.Minute), now)
+
+	assert.Equal(t, 2, order.SyncAttempts)
+	assert.Equal(t, &msg, order.LastSyncError)
+	if assert.NotNil(t, order.NextSyncAt) {
+		assert.Equal(t, now.Add(time.Minute), *order.NextSyncAt)
+	}
+}
+
+func TestConvertAccrualOrderStatusToOrderStatus(t *testing.T) {
+	tests := []struct {
+		status AccrualOrderStatus
+		want   OrderStatus
+	}{
+		{status: AccrualOrderStatusRegistered, want: OrderStatusNew},
+		{status: AccrualOrderStatusProcessing, want: OrderStatusProcessing},
+		{status: AccrualOrderStatusInvalid, want: OrderStatusInvalid},
+		{status: AccrualOrderStatusProcessed, want: OrderStatusProcessed},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			got, err := ConvertAccrualOrderStatusToOrderStatus(tt.status)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := ConvertAccrualOrderStatusToOrderStatus("UNKNOWN")
+	assert.Error(t, err)
+}
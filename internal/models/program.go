@@ -0,0 +1,36 @@
+package models
+
+import (
+	"math/big"
+	"time"
+)
+
+const DefaultProgramCode = "gophermart"
+
+// RateScale is the fixed-point denominator for program rates: an accrual rate
+// of RateScale means one program point per accrual unit, a conversion rate of
+// RateScale means one program point is worth one default program point.
+const RateScale = 10000
+
+type ProgramModel struct {
+	Code           string    `json:"-"`
+	Name           string    `json:"-"`
+	AccrualRate    int64     `json:"-"`
+	ConversionRate int64     `json:"-"`
+	CreatedAt      time.Time `json:"-"`
+}
+
+func (p *ProgramModel) ApplyAccrualRate(amount int64) int64 {
+	return amount * p.AccrualRate / RateScale
+}
+
+// ConvertTo returns what amount points of p are worth in points of to,
+// rounded down. ok is false when the result doesn't fit an int64.
+func (p *ProgramModel) ConvertTo(to *ProgramModel, amount int64) (converted int64, ok bool) {
+	value := new(big.Int).Mul(big.NewInt(amount), big.NewInt(p.ConversionRate))
+	value.Quo(value, big.NewInt(to.ConversionRate))
+	if !value.IsInt64() {
+		return 0, false
+	}
+	return value.Int64(), true
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgramModel_ConvertTo(t *testing.T) {
+	points := &ProgramModel{Code: DefaultProgramCode, ConversionRate: RateScale}
+	miles := &ProgramModel{Code: "miles", ConversionRate: 4 * RateScale}
+
+	tests := []struct {
+		name   string
+		from   *ProgramModel
+		to     *ProgramModel
+		amount int64
+		want   int64
+		wantOK bool
+	}{
+		{name: "to a more valuable program", from: points, to: miles, amount: 1000, want: 250, wantOK: true},
+		{name: "to a less valuable program", from: miles, to: points, amount: 250, want: 1000, wantOK: true},
+		{name: "rounds down", from: points, to: miles, amount: 3, want: 0, wantOK: true},
+		{name: "overflow", from: miles, to: points, amount: math.MaxInt64, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.from.ConvertTo(tt.to, tt.amount)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
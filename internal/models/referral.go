@@ -0,0 +1,69 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"time"
+)
+
+type ReferralStatus string
+
+const (
+	ReferralStatusPending  ReferralStatus = "pending"
+	ReferralStatusRewarded ReferralStatus = "rewarded"
+	ReferralStatusRejected ReferralStatus = "rejected"
+)
+
+// ReferralLimitWindow is the period the per-referrer reward limit applies to.
+const ReferralLimitWindow = 30 * 24 * time.Hour
+
+func NewReferralCode() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b), nil
+}
+
+type ReferralModel struct {
+	RefereeID    string         `json:"-"`
+	RefereeLogin string         `json:"-"`
+	ReferrerID   string         `json:"-"`
+	Status       ReferralStatus `json:"-"`
+	CreatedAt    time.Time      `json:"-"`
+	RewardedAt   *time.Time     `json:"-"`
+}
+
+type ReferralResponse struct {
+	Login      string         `json:"login"`
+	Status     ReferralStatus `json:"status"`
+	CreatedAt  time.Time      `json:"created_at"`
+	RewardedAt *time.Time     `json:"rewarded_at,omitempty"`
+}
+
+func (r *ReferralModel) ToResponse() *ReferralResponse {
+	return &ReferralResponse{
+		Login:      r.RefereeLogin,
+		Status:     r.Status,
+		CreatedAt:  r.CreatedAt,
+		RewardedAt: r.RewardedAt,
+	}
+}
+
+type ReferralModelList []*ReferralModel
+
+type ReferralsResponse struct {
+	ReferralCode string              `json:"referral_code"`
+	Referrals    []*ReferralResponse `json:"referrals"`
+}
+
+func (list ReferralModelList) ToResponse(referralCode string) *ReferralsResponse {
+	resp := &ReferralsResponse{
+		ReferralCode: referralCode,
+		Referrals:    make([]*ReferralResponse, len(list)),
+	}
+	for i, item := range list {
+		resp.Referrals[i] = item.ToResponse()
+	}
+	return resp
+}
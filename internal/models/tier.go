@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+type TierLevel string
+
+const (
+	TierLevelBronze TierLevel = "bronze"
+	TierLevelSilver TierLevel = "silver"
+	TierLevelGold   TierLevel = "gold"
+)
+
+// TierWindow is the rolling period of accruals a tier is computed from.
+const TierWindow = 365 * 24 * time.Hour
+
+type Tier struct {
+	Level TierLevel
+	// Threshold is the minimum rolling accrual total, in kopecks.
+	Threshold int64
+	// Multiplier is applied to accruals, scaled by RateScale.
+	Multiplier int64
+}
+
+// Tiers are ordered by ascending threshold.
+var Tiers = []Tier{
+	{Level: TierLevelBronze, Threshold: 0, Multiplier: 10000},
+	{Level: TierLevelSilver, Threshold: 100000, Multiplier: 11000},
+	{Level: TierLevelGold, Threshold: 500000, Multiplier: 12500},
+}
+
+func GetTier(level TierLevel) Tier {
+	for _, tier := range Tiers {
+		if tier.Level == level {
+			return tier
+		}
+	}
+	return Tiers[0]
+}
+
+func (t Tier) Next() (Tier, bool) {
+	for i, tier := range Tiers {
+		if tier.Level == t.Level && i+1 < len(Tiers) {
+			return Tiers[i+1], true
+		}
+	}
+	return Tier{}, false
+}
+
+func (t Tier) ApplyMultiplier(amount int64) int64 {
+	return amount * t.Multiplier / RateScale
+}
+
+type UserTierModel struct {
+	UserID    string    `json:"-"`
+	Level     TierLevel `json:"-"`
+	Accrued   int64     `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+type UserTierResponse struct {
+	Tier          TierLevel  `json:"tier"`
+	Multiplier    float64    `json:"multiplier"`
+	Accrued       Money      `json:"accrued"`
+	NextTier      *TierLevel `json:"next_tier,omitempty"`
+	NextThreshold *Money     `json:"next_threshold,omitempty"`
+	Remaining     *Money     `json:"remaining,omitempty"`
+}
+
+func (t *UserTierModel) ToResponse() *UserTierResponse {
+	tier := GetTier(t.Level)
+	resp := &UserTierResponse{
+		Tier:       tier.Level,
+		Multiplier: float64(tier.Multiplier) / RateScale,
+		Accrued:    Money(t.Accrued),
+	}
+
+	if next, ok := tier.Next(); ok {
+		threshold := Money(next.Threshold)
+		remaining := Money(max(next.Threshold-t.Accrued, 0))
+		resp.NextTier = &next.Level
+		resp.NextThreshold = &threshold
+		resp.Remaining = &remaining
+	}
+
+	return resp
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserTierModel_ToResponse(t *testing.T) {
+	t.Run("progress to next tier", func(t *testing.T) {
+		resp := (&UserTierModel{Level: TierLevelSilver, Accrued: 150000}).ToResponse()
+
+		assert.Equal(t, TierLevelSilver, resp.Tier)
+		assert.Equal(t, 1.1, resp.Multiplier)
+		require.NotNil(t, resp.NextTier)
+		assert.Equal(t, TierLevelGold, *resp.NextTier)
+		assert.Equal(t, Money(500000), *resp.NextThreshold)
+		assert.Equal(t, Money(350000), *resp.Remaining)
+	})
+
+	t.Run("top tier", func(t *testing.T) {
+		resp := (&UserTierModel{Level: TierLevelGold, Accrued: 900000}).ToResponse()
+
+		assert.Equal(t, TierLevelGold, resp.Tier)
+		assert.Nil(t, resp.NextTier)
+		assert.Nil(t, resp.Remaining)
+	})
+
+	t.Run("unknown level falls back to bronze", func(t *testing.T) {
+		resp := (&UserTierModel{Level: "platinum"}).ToResponse()
+
+		assert.Equal(t, TierLevelBronze, resp.Tier)
+		assert.Equal(t, 1.0, resp.Multiplier)
+	})
+}
+
+func TestTier_ApplyMultiplier(t *testing.T) {
+	assert.Equal(t, int64(10000), GetTier(TierLevelBronze).ApplyMultiplier(10000))
+	assert.Equal(t, int64(11000), GetTier(TierLevelSilver).ApplyMultiplier(10000))
+	assert.Equal(t, int64(12500), GetTier(TierLevelGold).ApplyMultiplier(10000))
+}
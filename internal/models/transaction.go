@@ -5,21 +5,32 @@ import "time"
 type TransactionType string
 
 const (
-	TransactionTypeAccrual  TransactionType = "accrual"
-	TransactionTypeWithdraw TransactionType = "withdraw"
+	TransactionTypeAccrual     TransactionType = "accrual"
+	TransactionTypeWithdraw    TransactionType = "withdraw"
+	TransactionTypeBonus       TransactionType = "bonus"
+	TransactionTypeReferral    TransactionType = "referral"
+	TransactionTypeTransferOut TransactionType = "transfer_out"
+	TransactionTypeTransferIn  TransactionType = "transfer_in"
+	// Conversions move points between two programs of the same user.
+	TransactionTypeConversionOut TransactionType = "conversion_out"
+	TransactionTypeConversionIn  TransactionType = "conversion_in"
 )
 
 type TransactionModel struct {
-	UUID      string          `json:"-"`
-	UserID    string          `json:"-"`
-	OrderID   string          `json:"-"`
-	Type      TransactionType `json:"-"`
-	Amount    int64           `json:"-"`
-	CreatedAt time.Time       `json:"-"`
+	UUID        string          `json:"-"`
+	UserID      string          `json:"-"`
+	ProgramCode string          `json:"-"`
+	OrderID     string          `json:"-"`
+	CampaignID  *string         `json:"-"`
+	TransferID  *string         `json:"-"`
+	Type        TransactionType `json:"-"`
+	Amount      int64           `json:"-"`
+	CreatedAt   time.Time       `json:"-"`
 }
 
 func (t *TransactionModel) SignedAmount() int64 {
-	if t.Type == TransactionTypeWithdraw {
+	switch t.Type {
+	case TransactionTypeWithdraw, TransactionTypeTransferOut, TransactionTypeConversionOut:
 		return -t.Amount
 	}
 	return t.Amount
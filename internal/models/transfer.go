@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+type TransferModel struct {
+	UUID           string    `json:"-"`
+	SenderID       string    `json:"-"`
+	RecipientID    string    `json:"-"`
+	RecipientLogin string    `json:"-"`
+	ProgramCode    string    `json:"-"`
+	Amount         int64     `json:"-"`
+	IdempotencyKey *string   `json:"-"`
+	CreatedAt      time.Time `json:"-"`
+}
+
+// Matches reports whether a replayed request carries the same parameters as
+// the stored transfer.
+func (t *TransferModel) Matches(other *TransferModel) bool {
+	return t.SenderID == other.SenderID &&
+		t.RecipientID == other.RecipientID &&
+		t.ProgramCode == other.ProgramCode &&
+		t.Amount == other.Amount
+}
+
+type TransferRequest struct {
+	Login   string `json:"login" binding:"required"`
+	Sum     Money  `json:"sum" binding:"required,gt=0"`
+	Program string `json:"program"`
+}
+
+func (req *TransferRequest) ToModel(senderID string, idempotencyKey string) *TransferModel {
+	programCode := req.Program
+	if programCode == "" {
+		programCode = DefaultProgramCode
+	}
+
+	transfer := &TransferModel{
+		SenderID:       senderID,
+		RecipientLogin: req.Login,
+		ProgramCode:    programCode,
+		Amount:         req.Sum.Kopecks(),
+		CreatedAt:      time.Now(),
+	}
+	if idempotencyKey != "" {
+		transfer.IdempotencyKey = &idempotencyKey
+	}
+	return transfer
+}
+
+type TransferResponse struct {
+	ID          string    `json:"id"`
+	Login       string    `json:"login"`
+	Program     string    `json:"program"`
+	Sum         Money     `json:"sum"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+func (t *TransferModel) ToResponse() *TransferResponse {
+	return &TransferResponse{
+		ID:          t.UUID,
+		Login:       t.RecipientLogin,
+		Program:     t.ProgramCode,
+		Sum:         Money(t.Amount),
+		ProcessedAt: t.CreatedAt,
+	}
+}
@@ -10,7 +10,7 @@ type UserModel struct {
 	UUID           string    `json:"-"`
 	Login          string    `json:"-"`
 	HashedPassword string    `json:"-"`
-	Balance        int64     `json:"-"`
+	ReferralCode   string    `json:"-"`
 	CreatedAt      time.Time `json:"-"`
 }
 
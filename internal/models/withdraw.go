@@ -3,37 +3,45 @@ package models
 import "time"
 
 type WithdrawModel struct {
-	UserID    string    `json:"-"`
-	OrderID   string    `json:"-"`
-	Sum       int64     `json:"-"`
-	CreatedAt time.Time `json:"-"`
+	UserID      string    `json:"-"`
+	ProgramCode string    `json:"-"`
+	OrderID     string    `json:"-"`
+	Sum         int64     `json:"-"`
+	CreatedAt   time.Time `json:"-"`
 }
 
 func (w *WithdrawModel) ToResponse() *WithdrawResponse {
 	return &WithdrawResponse{
 		OrderID:     w.OrderID,
-		Sum:         KopecksToRubles(w.Sum),
+		Sum:         Money(w.Sum),
 		ProcessedAt: w.CreatedAt,
 	}
 }
 
 type WithdrawRequest struct {
-	OrderID string  `json:"order" binding:"required,numeric"`
-	Sum     float64 `json:"sum" binding:"required,gt=0"`
+	OrderID string `json:"order" binding:"required,numeric"`
+	Sum     Money  `json:"sum" binding:"required,gt=0"`
+	Program string `json:"program"`
 }
 
 func (req *WithdrawRequest) ToModel(userID string) *WithdrawModel {
+	programCode := req.Program
+	if programCode == "" {
+		programCode = DefaultProgramCode
+	}
+
 	return &WithdrawModel{
-		UserID:    userID,
-		OrderID:   req.OrderID,
-		Sum:       RublesToKopecks(req.Sum),
-		CreatedAt: time.Now(),
+		UserID:      userID,
+		ProgramCode: programCode,
+		OrderID:     req.OrderID,
+		Sum:         req.Sum.Kopecks(),
+		CreatedAt:   time.Now(),
 	}
 }
 
 type WithdrawResponse struct {
 	OrderID     string    `json:"order"`
-	Sum         float64   `json:"sum"`
+	Sum         Money     `json:"sum"`
 	ProcessedAt time.Time `json:"processed_at"`
 }
 
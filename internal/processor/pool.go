@@ -0,0 +1,317 @@
+package processor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/logging"
+	"github.com/etoneja/go-gophermart/internal/metrics"
+	"github.com/rs/zerolog"
+)
+
+const (
+	adjustInterval   = 5 * time.Second
+	busyWaitInterval = time.Second
+)
+
+// PoolStats is a snapshot of the sync pool for monitoring.
+type PoolStats struct {
+	Concurrency    int `json:"concurrency"`
+	Active         int `json:"active"`
+	QueueDepth     int `json:"queue_depth"`
+	QueueCapacity  int `json:"queue_capacity"`
+	MinConcurrency int `json:"min_concurrency"`
+	MaxConcurrency int `json:"max_concurrency"`
+}
+
+// poolWindow collects the outcomes of sync calls between two concurrency
+// adjustments.
+type poolWindow struct {
+	completed    int
+	throttled    int
+	totalLatency time.Duration
+}
+
+func (w poolWindow) avgLatency() time.Duration {
+	if w.completed == 0 {
+		return 0
+	}
+	return w.totalLatency / time.Duration(w.completed)
+}
+
+// syncOrdersFunc syncs a batch of claimed orders and reports whether the
+// accrual system throttled any of the lookups.
+type syncOrdersFunc func(ctx context.Context, orderIDs []string) (throttled bool, err error)
+
+// syncPool syncs claimed orders from a queue with a number of workers that
+// adapts to the accrual system: it backs off multiplicatively when requests
+// get throttled, by one when latency exceeds the target and grows while
+// batches are waiting in the queue. Each worker takes up to batchSize queued
+// orders per sync call.
+type syncPool struct {
+	syncOrders    syncOrdersFunc
+	batchSize     func(ctx context.Context) int
+	isBusy        func() bool
+	queue         chan string
+	minWorkers    int
+	maxWorkers    int
+	latencyTarget time.Duration
+	// lastBatchSize is the batch size workers last used, to count queued
+	// batches.
+	lastBatchSize atomic.Int64
+
+	mu      sync.Mutex
+	target  int
+	workers int
+	active  int
+	window  poolWindow
+
+	wg     sync.WaitGroup
+	logger zerolog.Logger
+}
+
+func newSyncPool(
+	syncOrders syncOrdersFunc,
+	batchSize func(ctx context.Context) int,
+	isBusy func() bool,
+	minWorkers, maxWorkers, initial, queueSize int,
+	latencyTarget time.Duration,
+	logger zerolog.Logger,
+) *syncPool {
+	minWorkers = max(minWorkers, 1)
+	maxWorkers = max(maxWorkers, minWorkers)
+
+	p := &syncPool{
+		syncOrders:    syncOrders,
+		batchSize:     batchSize,
+		isBusy:        isBusy,
+		queue:         make(chan string, max(queueSize, 1)),
+		minWorkers:    minWorkers,
+		maxWorkers:    maxWorkers,
+		latencyTarget: latencyTarget,
+		target:        min(max(initial, minWorkers), maxWorkers),
+		logger:        logger,
+	}
+	p.lastBatchSize.Store(1)
+	return p
+}
+
+// free returns how many orders can be queued without blocking.
+func (p *syncPool) free() int {
+	return cap(p.queue) - len(p.queue)
+}
+
+func (p *syncPool) push(ctx context.Context, orderID string) error {
+	select {
+	case p.queue <- orderID:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *syncPool) stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return PoolStats{
+		Concurrency:    p.target,
+		Active:         p.active,
+		QueueDepth:     len(p.queue),
+		QueueCapacity:  cap(p.queue),
+		MinConcurrency: p.minWorkers,
+		MaxConcurrency: p.maxWorkers,
+	}
+}
+
+// run keeps the workers running and adjusts their number until ctx is done.
+// Orders left in the queue keep their claim until the sync lease expires.
+func (p *syncPool) run(ctx context.Context) {
+	p.wg.Add(1)
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(adjustInterval)
+	defer ticker.Stop()
+
+	for {
+		p.scale(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.adjust(ctx)
+		}
+	}
+}
+
+// scale starts workers up to the target, surplus workers exit on their own
+// after finishing their current order.
+func (p *syncPool) scale(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for ; p.workers < p.target; p.workers++ {
+		p.wg.Add(1)
+		go p.work(ctx)
+	}
+}
+
+func (p *syncPool) adjust(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	depth := len(p.queue)
+	batches := ceilDiv(depth, int(p.lastBatchSize.Load()))
+	prev := p.target
+	p.target = nextConcurrency(p.target, p.minWorkers, p.maxWorkers, p.active, batches, p.window, p.latencyTarget)
+
+	metrics.SyncConcurrency.Set(float64(p.target))
+	metrics.SyncQueueDepth.Set(float64(depth))
+
+	logger := logging.FromContext(ctx, &p.logger)
+	event := logger.Debug()
+	if p.target != prev {
+		event = logger.Info()
+	}
+	event.
+		Int("concurrency", p.target).
+		Int("previous", prev).
+		Int("queueDepth", depth).
+		Int("queuedBatches", batches).
+		Int("completed", p.window.completed).
+		Int("throttled", p.window.throttled).
+		Dur("avgLatency", p.window.avgLatency()).
+		Msg("Sync pool adjusted")
+
+	p.window = poolWindow{}
+}
+
+// nextConcurrency decides the worker count for the next interval from the
+// number of batches waiting in the queue and the outcomes of the last one.
+func nextConcurrency(current, minWorkers, maxWorkers, active, queuedBatches int, window poolWindow, latencyTarget time.Duration) int {
+	next := current
+	switch {
+	case window.throttled > 0:
+		next = current / 2
+	case window.completed > 0 && latencyTarget > 0 && window.avgLatency() > latencyTarget:
+		next = current - 1
+	case queuedBatches >= current:
+		next = current * 2
+	case queuedBatches > 0:
+		next = current + 1
+	case active < current:
+		next = current - 1
+	}
+	return min(max(next, minWorkers), maxWorkers)
+}
+
+func (p *syncPool) work(ctx context.Context) {
+	defer p.wg.Done()
+
+	for {
+		if p.retire() {
+			return
+		}
+
+		var orderID string
+		select {
+		case <-ctx.Done():
+			p.exit()
+			return
+		case orderID = <-p.queue:
+		}
+
+		if !p.waitNotBusy(ctx) {
+			p.exit()
+			return
+		}
+
+		size := max(p.batchSize(ctx), 1)
+		p.lastBatchSize.Store(int64(size))
+		orderIDs := p.take(orderID, size)
+
+		logger := logging.FromContext(ctx, &p.logger).With().Strs("orderIDs", orderIDs).Logger()
+		batchCtx := logging.WithLogger(ctx, logger)
+
+		p.setActive(1)
+		start := time.Now()
+		throttled, err := p.syncOrders(batchCtx, orderIDs)
+		p.record(time.Since(start), throttled)
+		p.setActive(-1)
+
+		if err != nil && ctx.Err() == nil {
+			logger.Error().Err(err).Msg("Orders processing failed")
+		}
+	}
+}
+
+// take adds orders already waiting in the queue to first, up to size in
+// total, without waiting for more.
+func (p *syncPool) take(first string, size int) []string {
+	orderIDs := []string{first}
+	for len(orderIDs) < size {
+		select {
+		case orderID := <-p.queue:
+			orderIDs = append(orderIDs, orderID)
+		default:
+			return orderIDs
+		}
+	}
+	return orderIDs
+}
+
+// retire stops the worker when the pool has been scaled down.
+func (p *syncPool) retire() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.workers > p.target {
+		p.workers--
+		return true
+	}
+	return false
+}
+
+func (p *syncPool) exit() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.workers--
+}
+
+// waitNotBusy holds the worker while the accrual system is rate limited or
+// its breaker is open, so queued orders aren't burnt on failing requests.
+func (p *syncPool) waitNotBusy(ctx context.Context) bool {
+	for p.isBusy() {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(busyWaitInterval):
+		}
+	}
+	return true
+}
+
+func (p *syncPool) setActive(delta int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active += delta
+}
+
+func (p *syncPool) record(latency time.Duration, throttled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.window.completed++
+	p.window.totalLatency += latency
+	if throttled {
+		p.window.throttled++
+	}
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / max(b, 1)
+}
+
+func (p *syncPool) stop() {
+	p.wg.Wait()
+}
@@ -0,0 +1,176 @@
+package processor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextConcurrency(t *testing.T) {
+	const latencyTarget = time.Second
+
+	tests := []struct {
+		name    string
+		current int
+		active  int
+		queued  int
+		window  poolWindow
+		want    int
+	}{
+		{
+			name:    "throttled halves",
+			current: 8,
+			active:  8,
+			queued:  10,
+			window:  poolWindow{completed: 10, throttled: 1, totalLatency: time.Second},
+			want:    4,
+		},
+		{
+			name:    "throttled keeps min",
+			current: 3,
+			window:  poolWindow{throttled: 2},
+			want:    2,
+		},
+		{
+			name:    "slow accrual decreases",
+			current: 8,
+			active:  8,
+			queued:  10,
+			window:  poolWindow{completed: 4, totalLatency: 8 * time.Second},
+			want:    7,
+		},
+		{
+			name:    "many queued batches double",
+			current: 4,
+			active:  4,
+			queued:  6,
+			window:  poolWindow{completed: 4, totalLatency: time.Second},
+			want:    8,
+		},
+		{
+			name:    "growth capped at max",
+			current: 12,
+			active:  12,
+			queued:  30,
+			want:    16,
+		},
+		{
+			name:    "few queued batches increment",
+			current: 4,
+			active:  4,
+			queued:  2,
+			want:    5,
+		},
+		{
+			name:    "idle workers decrease",
+			current: 4,
+			active:  1,
+			want:    3,
+		},
+		{
+			name:    "busy with empty queue holds",
+			current: 4,
+			active:  4,
+			want:    4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextConcurrency(tt.current, 2, 16, tt.active, tt.queued, tt.window, latencyTarget)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSyncPool_SyncsQueuedOrders(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		synced  []string
+		calls   [][]string
+		running atomic.Int32
+		peak    atomic.Int32
+	)
+	syncOrders := func(ctx context.Context, orderIDs []string) (bool, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		if n > peak.Load() {
+			peak.Store(n)
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		synced = append(synced, orderIDs...)
+		calls = append(calls, orderIDs)
+		return false, nil
+	}
+	batchSize := func(context.Context) int { return 2 }
+
+	pool := newSyncPool(syncOrders, batchSize, func() bool { return false }, 1, 4, 2, 8, time.Second, zerolog.Nop())
+
+	ids := []string{"1", "2", "3", "4", "5", "6"}
+	for _, id := range ids {
+		require.NoError(t, pool.push(context.Background(), id))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go pool.run(ctx)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(synced) == len(ids)
+	}, 5*time.Second, 5*time.Millisecond)
+
+	cancel()
+	pool.stop()
+
+	assert.ElementsMatch(t, ids, synced)
+	assert.Len(t, calls, 3, "queued orders are synced in batches")
+	for _, call := range calls {
+		assert.Len(t, call, 2)
+	}
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+
+	stats := pool.stats()
+	assert.Equal(t, 2, stats.Concurrency)
+	assert.Zero(t, stats.QueueDepth)
+	assert.Equal(t, 8, stats.QueueCapacity)
+}
+
+func TestSyncPool_RecordsThrottledCalls(t *testing.T) {
+	done := make(chan struct{}, 2)
+	syncOrders := func(ctx context.Context, orderIDs []string) (bool, error) {
+		defer func() { done <- struct{}{} }()
+		return orderIDs[0] == "throttled", nil
+	}
+	batchSize := func(context.Context) int { return 1 }
+
+	pool := newSyncPool(syncOrders, batchSize, func() bool { return false }, 1, 1, 1, 2, time.Second, zerolog.Nop())
+	require.NoError(t, pool.push(context.Background(), "throttled"))
+	require.NoError(t, pool.push(context.Background(), "ok"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go pool.run(ctx)
+	for range 2 {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("orders were not synced")
+		}
+	}
+	cancel()
+	pool.stop()
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	assert.Equal(t, 2, pool.window.completed)
+	assert.Equal(t, 1, pool.window.throttled)
+}
@@ -2,103 +2,197 @@ package processor
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/etoneja/go-gophermart/internal/config"
+	"github.com/etoneja/go-gophermart/internal/jobs"
+	"github.com/etoneja/go-gophermart/internal/logging"
+	"github.com/etoneja/go-gophermart/internal/metrics"
 	"github.com/etoneja/go-gophermart/internal/service"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog"
 )
 
+const (
+	// busyRecheckDelay is how soon to look again while the accrual system is
+	// rate limited or its circuit breaker is open.
+	busyRecheckDelay = 5 * time.Second
+	// poolFullRecheckDelay is how soon to feed again while the sync pool
+	// queue is full.
+	poolFullRecheckDelay = time.Second
+	// heartbeatStaleIntervals is how many WorkerIntervals may pass without a
+	// finished orders sync job run before the processor is reported as stuck.
+	heartbeatStaleIntervals = 3
+)
+
 type OrderProcessor struct {
-	cfg    *config.Config
-	svc    service.Servicer
-	wg     sync.WaitGroup
-	logger zerolog.Logger
+	cfg  *config.Config
+	svc  service.Servicer
+	pool *syncPool
+	wg   sync.WaitGroup
+	// heartbeat is the unix nano time the last orders sync job run finished.
+	heartbeat atomic.Int64
+	logger    zerolog.Logger
 }
 
-func NewOrderProcessor(cfg *config.Config, dbPool *pgxpool.Pool, logger zerolog.Logger) *OrderProcessor {
-
-	svc := service.NewService(cfg, dbPool, logger)
+// NewOrderProcessor registers the orders sync job on the jobs worker. The job
+// runs as soon as orders are created or retried and whenever the next
+// scheduled sync is due, and feeds claimed orders to the sync pool.
+func NewOrderProcessor(cfg *config.Config, svc service.Servicer, worker *jobs.Worker, logger zerolog.Logger) *OrderProcessor {
+	syncOrders := func(ctx context.Context, orderIDs []string) (bool, error) {
+		report, err := svc.SyncOrders(ctx, orderIDs)
+		return report.RateLimited > 0, err
+	}
 
-	return &OrderProcessor{
-		cfg:    cfg,
-		svc:    svc,
+	p := &OrderProcessor{
+		cfg: cfg,
+		svc: svc,
+		pool: newSyncPool(
+			syncOrders,
+			svc.SyncBatchSize,
+			svc.IsAccrualSytemBusy,
+			cfg.SyncMinConcurrency,
+			cfg.SyncMaxConcurrency,
+			cfg.WorkerPoolSize,
+			syncQueueSize(cfg),
+			cfg.SyncLatencyTarget,
+			logger),
 		logger: logger,
 	}
+	worker.Register(service.SyncOrdersJob, p.handleSyncOrders)
+	return p
+}
+
+// syncQueueSize fits two claim batches or two orders per worker, whichever
+// is more, so workers keep busy while the next batch is claimed.
+func syncQueueSize(cfg *config.Config) int {
+	return 2 * max(cfg.SyncMaxConcurrency, cfg.SyncBatchSize)
+}
+
+// Stats reports the current sync concurrency and queue depth.
+func (p *OrderProcessor) Stats() PoolStats {
+	return p.pool.stats()
+}
+
+// CheckHeartbeat returns an error unless an orders sync job run has finished
+// recently. The job runs at least every WorkerInterval, so a hung run or a
+// stuck jobs worker turns the heartbeat stale.
+func (p *OrderProcessor) CheckHeartbeat(context.Context) error {
+	last := p.heartbeat.Load()
+	if last == 0 {
+		return errors.New("order processor hasn't started")
+	}
+	if age := time.Since(time.Unix(0, last)); age > heartbeatStaleIntervals*p.cfg.WorkerInterval {
+		return fmt.Errorf("last order processor heartbeat was %s ago", age.Round(time.Second))
+	}
+	return nil
+}
+
+// log returns the logger attached to ctx, the one of the sync job run when
+// called from its handler.
+func (p *OrderProcessor) log(ctx context.Context) *zerolog.Logger {
+	return logging.FromContext(ctx, &p.logger)
 }
 
+func (p *OrderProcessor) beat() {
+	p.heartbeat.Store(time.Now().UnixNano())
+}
+
+// Run makes sure the orders sync job exists at startup and every
+// WorkerInterval, recreating it after it has been dead-lettered. The job
+// schedules itself otherwise.
 func (p *OrderProcessor) Run(ctx context.Context) {
 	p.wg.Add(1)
 	defer p.wg.Done()
 
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.pool.run(ctx)
+	}()
+
 	ticker := time.NewTicker(p.cfg.WorkerInterval)
 	defer ticker.Stop()
 
 	for {
+		if err := p.svc.EnsureOrdersSync(ctx); err != nil && ctx.Err() == nil {
+			p.log(ctx).Error().Err(err).Msg("Failed to ensure orders sync job")
+		}
+
 		select {
 		case <-ctx.Done():
-			p.logger.Info().Msg("Order processor stopped")
+			p.log(ctx).Info().Msg("Order processor stopped")
 			return
 		case <-ticker.C:
-			p.processOrders(ctx)
 		}
 	}
 }
 
-func (p *OrderProcessor) processOrders(ctx context.Context) {
+func (p *OrderProcessor) handleSyncOrders(ctx context.Context, job *jobs.Job) error {
+	delay, err := p.processOrders(ctx)
+	p.beat()
+	if err != nil {
+		return err
+	}
+	return jobs.Snooze(delay)
+}
+
+// processOrders claims due orders to fill the sync pool queue and returns the
+// delay before the next run.
+func (p *OrderProcessor) processOrders(ctx context.Context) (time.Duration, error) {
 	if p.svc.IsAccrualSytemBusy() {
-		p.logger.Info().Msg("Accrual system is busy, skip processing")
-		return
+		p.log(ctx).Info().Msg("Accrual system is busy, skip processing")
+		return min(busyRecheckDelay, p.cfg.WorkerInterval), nil
+	}
+
+	free := p.pool.free()
+	if free == 0 {
+		return poolFullRecheckDelay, nil
 	}
 
-	orders, err := p.svc.GetOrdersToSync(ctx, p.cfg.WorkerPoolSize)
+	limit := min(free, p.cfg.SyncBatchSize)
+	orders, err := p.svc.ClaimOrdersToSync(ctx, limit)
 	if err != nil {
-		p.logger.Error().Err(err).Msg("Failed to get pending orders")
-		return
+		return 0, fmt.Errorf("failed to claim orders: %w", err)
 	}
+	metrics.SyncBatchSize.Observe(float64(len(orders)))
 
 	if len(orders) == 0 {
-		p.logger.Info().Msg("No orders to process")
-		return
+		p.log(ctx).Debug().Msg("No orders to process")
+		return p.untilNextSync(ctx)
 	}
 
-	p.logger.Info().Int("count", len(orders)).Msg("Processing orders")
-
-	var (
-		batchWg sync.WaitGroup
-		sem     = make(chan struct{}, p.cfg.WorkerPoolSize)
-	)
+	p.log(ctx).Debug().Int("count", len(orders)).Msg("Queueing orders")
 
 	for _, order := range orders {
-		select {
-		case <-ctx.Done():
-			batchWg.Wait()
-			return
-		case sem <- struct{}{}:
-			batchWg.Add(1)
-
-			go func(orderID string) {
-				defer batchWg.Done()
-				defer func() { <-sem }()
-
-				if p.svc.IsAccrualSytemBusy() {
-					p.logger.Info().Str("orderID", orderID).Msg("Skip order due to accrual system is busy")
-					return
-				}
-
-				if err := p.svc.SyncOrder(ctx, orderID); err != nil {
-					p.logger.Error().Err(err).Str("orderID", orderID).Msg("Order processing failed")
-				}
-			}(order.ID)
+		if err := p.pool.push(ctx, order.ID); err != nil {
+			return 0, err
 		}
 	}
 
-	batchWg.Wait()
+	if len(orders) == limit {
+		return 0, nil
+	}
+	return p.untilNextSync(ctx)
+}
 
+// untilNextSync returns the time left to the earliest scheduled sync, capped
+// by the sweep interval.
+func (p *OrderProcessor) untilNextSync(ctx context.Context) (time.Duration, error) {
+	nextSyncAt, err := p.svc.GetNextSyncAt(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get next sync time: %w", err)
+	}
+	if nextSyncAt == nil {
+		return p.cfg.WorkerInterval, nil
+	}
+	return min(max(time.Until(*nextSyncAt), 0), p.cfg.WorkerInterval), nil
 }
 
 func (p *OrderProcessor) Stop() {
 	p.wg.Wait()
+	p.pool.stop()
 }
@@ -0,0 +1,108 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/config"
+	"github.com/etoneja/go-gophermart/internal/models"
+	"github.com/etoneja/go-gophermart/internal/service/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderProcessor_ProcessOrdersDelay(t *testing.T) {
+	const interval = 30 * time.Second
+	nextSyncAt := time.Now().Add(10 * time.Second)
+
+	tests := []struct {
+		name     string
+		busy     bool
+		orders   models.OrderModelList
+		getErr   error
+		wantErr  bool
+		nextSync *time.Time
+		wantMin  time.Duration
+		wantMax  time.Duration
+		queued   int
+	}{
+		{name: "busy", busy: true, wantMin: busyRecheckDelay, wantMax: busyRecheckDelay},
+		{name: "pool full", queued: 4, wantMin: poolFullRecheckDelay, wantMax: poolFullRecheckDelay},
+		{name: "db error", getErr: errors.New("db down"), wantErr: true},
+		{name: "idle", wantMin: interval, wantMax: interval},
+		{name: "idle with scheduled retry", nextSync: &nextSyncAt, wantMin: 9 * time.Second, wantMax: 10 * time.Second},
+		{
+			name:   "full batch",
+			orders: models.OrderModelList{{ID: "1"}, {ID: "2"}},
+		},
+		{
+			name:    "partial batch",
+			orders:  models.OrderModelList{{ID: "1"}},
+			wantMin: interval,
+			wantMax: interval,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockSvc := mocks.NewMockServicer(ctrl)
+			p := &OrderProcessor{
+				cfg:    &config.Config{WorkerInterval: interval, SyncBatchSize: 2},
+				svc:    mockSvc,
+				pool:   newSyncPool(nil, nil, nil, 1, 2, 1, 4, 0, zerolog.Nop()),
+				logger: zerolog.Nop(),
+			}
+			for i := range tt.queued {
+				require.NoError(t, p.pool.push(context.Background(), strconv.Itoa(i)))
+			}
+
+			claims := !tt.busy && tt.queued < cap(p.pool.queue)
+			mockSvc.EXPECT().IsAccrualSytemBusy().Return(tt.busy)
+			if claims {
+				mockSvc.EXPECT().ClaimOrdersToSync(gomock.Any(), 2).Return(tt.orders, tt.getErr)
+			}
+			if claims && tt.getErr == nil && len(tt.orders) < 2 {
+				mockSvc.EXPECT().GetNextSyncAt(gomock.Any()).Return(tt.nextSync, nil)
+			}
+
+			delay, err := p.processOrders(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, delay, tt.wantMin)
+			assert.LessOrEqual(t, delay, tt.wantMax)
+			assert.Equal(t, tt.queued+len(tt.orders), p.Stats().QueueDepth)
+		})
+	}
+}
+
+func TestOrderProcessor_Heartbeat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := mocks.NewMockServicer(ctrl)
+	p := &OrderProcessor{
+		cfg:    &config.Config{WorkerInterval: time.Minute, SyncBatchSize: 2},
+		svc:    mockSvc,
+		pool:   newSyncPool(nil, nil, nil, 1, 2, 1, 4, 0, zerolog.Nop()),
+		logger: zerolog.Nop(),
+	}
+	assert.Error(t, p.CheckHeartbeat(context.Background()), "no sync job run has finished yet")
+
+	mockSvc.EXPECT().IsAccrualSytemBusy().Return(true)
+	require.Error(t, p.handleSyncOrders(context.Background(), nil), "a busy run snoozes the job")
+	assert.NoError(t, p.CheckHeartbeat(context.Background()))
+
+	p.heartbeat.Store(time.Now().Add(-4 * time.Minute).UnixNano())
+	assert.Error(t, p.CheckHeartbeat(context.Background()))
+}
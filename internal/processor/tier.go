@@ -0,0 +1,83 @@
+package processor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/config"
+	"github.com/etoneja/go-gophermart/internal/jobs"
+	"github.com/etoneja/go-gophermart/internal/logging"
+	"github.com/etoneja/go-gophermart/internal/service"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type TierProcessor struct {
+	cfg    *config.Config
+	svc    service.Servicer
+	wg     sync.WaitGroup
+	logger zerolog.Logger
+}
+
+// NewTierProcessor registers the tier recalculation job on the jobs worker.
+// The job is unique, so only one replica recalculates at a time, and it
+// snoozes for TierRecalcInterval between runs.
+func NewTierProcessor(cfg *config.Config, svc service.Servicer, worker *jobs.Worker, logger zerolog.Logger) *TierProcessor {
+	p := &TierProcessor{
+		cfg:    cfg,
+		svc:    svc,
+		logger: logger,
+	}
+	worker.Register(service.RecalculateTiersJob, p.handleRecalculate)
+	return p
+}
+
+// Run makes sure the tier recalculation job exists at startup and every
+// WorkerInterval, recreating it after it has been dead-lettered. The job
+// schedules itself otherwise.
+func (p *TierProcessor) Run(ctx context.Context) {
+	p.wg.Add(1)
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.cfg.WorkerInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.svc.EnsureTiersRecalc(ctx); err != nil && ctx.Err() == nil {
+			p.log(ctx).Error().Err(err).Msg("Failed to ensure tier recalculation job")
+		}
+
+		select {
+		case <-ctx.Done():
+			p.log(ctx).Info().Msg("Tier processor stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// log returns the logger attached to ctx, the run-scoped one inside
+// handleRecalculate.
+func (p *TierProcessor) log(ctx context.Context) *zerolog.Logger {
+	return logging.FromContext(ctx, &p.logger)
+}
+
+// handleRecalculate tags the run with an ID, so the service and repository
+// logs of one recalculation can be told apart from the next. Failed runs are
+// retried with the jobs worker's backoff.
+func (p *TierProcessor) handleRecalculate(ctx context.Context, job *jobs.Job) error {
+	ctx = logging.WithLogger(ctx, p.log(ctx).With().Str("run_id", uuid.NewString()).Logger())
+
+	updated, err := p.svc.RecalculateTiers(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.log(ctx).Info().Int64("count", updated).Msg("Tiers recalculated")
+	return jobs.Snooze(p.cfg.TierRecalcInterval)
+}
+
+func (p *TierProcessor) Stop() {
+	p.wg.Wait()
+}
@@ -0,0 +1,36 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/config"
+	"github.com/etoneja/go-gophermart/internal/jobs"
+	"github.com/etoneja/go-gophermart/internal/service/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTierProcessor_HandleRecalculate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := mocks.NewMockServicer(ctrl)
+	p := &TierProcessor{
+		cfg:    &config.Config{TierRecalcInterval: time.Hour},
+		svc:    mockSvc,
+		logger: zerolog.Nop(),
+	}
+
+	mockSvc.EXPECT().RecalculateTiers(gomock.Any()).Return(int64(3), nil)
+	err := p.handleRecalculate(context.Background(), &jobs.Job{})
+	assert.EqualError(t, err, jobs.Snooze(time.Hour).Error(), "a finished run snoozes until the next one")
+
+	dbErr := errors.New("db down")
+	mockSvc.EXPECT().RecalculateTiers(gomock.Any()).Return(int64(0), dbErr)
+	err = p.handleRecalculate(context.Background(), &jobs.Job{})
+	assert.ErrorIs(t, err, dbErr, "a failed run is retried with backoff")
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/etoneja/go-gophermart/internal/errs"
+	"github.com/etoneja/go-gophermart/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+type AccountRepository struct{}
+
+func NewAccountRepository() *AccountRepository {
+	return &AccountRepository{}
+}
+
+type GetAccountOptions struct {
+	UserID        string
+	ProgramCode   string
+	LockForUpdate bool
+}
+
+// EnsureAccount creates the account if it does not exist yet.
+func (r *AccountRepository) EnsureAccount(ctx context.Context, tx pgx.Tx, account *models.AccountModel) error {
+	query := `
+		INSERT INTO accounts (
+			user_id,
+			program_code,
+			balance,
+			created_at
+		)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, program_code) DO NOTHING
+	`
+
+	_, err := tx.Exec(
+		ctx,
+		query,
+		account.UserID,
+		account.ProgramCode,
+		account.Balance,
+		account.CreatedAt)
+
+	return err
+}
+
+func (r *AccountRepository) GetAccount(ctx context.Context, tx pgx.Tx, opts GetAccountOptions) (*models.AccountModel, error) {
+	query := `
+		SELECT
+			user_id,
+			program_code,
+			balance,
+			created_at
+		FROM accounts
+		WHERE user_id = $1 AND program_code = $2
+	`
+
+	if opts.LockForUpdate {
+		query += " FOR UPDATE"
+	}
+
+	var account models.AccountModel
+	err := tx.QueryRow(ctx, query, opts.UserID, opts.ProgramCode).Scan(
+		&account.UserID,
+		&account.ProgramCode,
+		&account.Balance,
+		&account.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+func (r *AccountRepository) GetAccountBalance(ctx context.Context, tx pgx.Tx, userID, programCode string) (*models.BalanceModel, error) {
+	query := `
+		SELECT
+			COALESCE(
+				(SELECT balance
+				FROM accounts
+				WHERE user_id = $1 AND program_code = $2),
+				0
+			) AS current,
+			COALESCE(
+				(SELECT SUM(amount)
+				FROM transactions
+				WHERE user_id = $1 AND program_code = $2 AND type = $3),
+				0
+			) AS withdrawn
+	`
+
+	balance := models.BalanceModel{ProgramCode: programCode}
+	err := tx.QueryRow(ctx, query, userID, programCode, models.TransactionTypeWithdraw).Scan(
+		&balance.Current,
+		&balance.Withdrawn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &balance, nil
+}
+
+func (r *AccountRepository) GetAccountBalances(ctx context.Context, tx pgx.Tx, userID string) (models.BalanceModelList, error) {
+	query := `
+		SELECT
+			a.program_code,
+			a.balance,
+			COALESCE(
+				(SELECT SUM(t.amount)
+				FROM transactions AS t
+				WHERE t.user_id = a.user_id AND t.program_code = a.program_code AND t.type = $2),
+				0
+			) AS withdrawn
+		FROM accounts AS a
+		WHERE a.user_id = $1
+		ORDER BY a.program_code
+	`
+
+	rows, err := tx.Query(ctx, query, userID, models.TransactionTypeWithdraw)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var balances models.BalanceModelList
+	for rows.Next() {
+		var balance models.BalanceModel
+		if err := rows.Scan(
+			&balance.ProgramCode,
+			&balance.Current,
+			&balance.Withdrawn,
+		); err != nil {
+			return nil, err
+		}
+		balances = append(balances, &balance)
+	}
+
+	return balances, nil
+}
+
+func (r *AccountRepository) UpdateAccountBalance(ctx context.Context, tx pgx.Tx, transaction *models.TransactionModel) error {
+	query := `
+		UPDATE accounts
+		SET balance = balance + $1
+		WHERE user_id = $2 AND program_code = $3
+	`
+
+	res, err := tx.Exec(
+		ctx,
+		query,
+		transaction.SignedAmount(),
+		transaction.UserID,
+		transaction.ProgramCode)
+
+	if err != nil {
+		return fmt.Errorf("failed to update balance: %w", err)
+	}
+	if res.RowsAffected() != 1 {
+		return errs.ErrNotOnlyOneRowAffected
+	}
+	return nil
+}
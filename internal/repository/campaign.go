@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/errs"
+	"github.com/etoneja/go-gophermart/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type CampaignRepository struct{}
+
+func NewCampaignRepository() *CampaignRepository {
+	return &CampaignRepository{}
+}
+
+const campaignColumns = `
+			uuid,
+			name,
+			program_code,
+			starts_at,
+			ends_at,
+			active,
+			eligibility,
+			bonus,
+			created_at,
+			updated_at
+`
+
+func (r *CampaignRepository) CreateCampaign(ctx context.Context, tx pgx.Tx, campaign *models.CampaignModel) error {
+	query := `
+		INSERT INTO campaigns (` + campaignColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := tx.Exec(
+		ctx,
+		query,
+		campaign.UUID,
+		campaign.Name,
+		campaign.ProgramCode,
+		campaign.StartsAt,
+		campaign.EndsAt,
+		campaign.Active,
+		campaign.Eligibility,
+		campaign.Bonus,
+		campaign.CreatedAt,
+		campaign.UpdatedAt)
+
+	return err
+}
+
+func (r *CampaignRepository) GetCampaign(ctx context.Context, tx pgx.Tx, campaignID string) (*models.CampaignModel, error) {
+	query := `
+		SELECT ` + campaignColumns + `
+		FROM campaigns
+		WHERE uuid = $1
+	`
+
+	campaign, err := r.scanCampaign(tx.QueryRow(ctx, query, campaignID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.ErrNoRows
+		}
+		return nil, err
+	}
+
+	return campaign, nil
+}
+
+func (r *CampaignRepository) GetCampaigns(ctx context.Context, tx pgx.Tx) (models.CampaignModelList, error) {
+	query := `
+		SELECT ` + campaignColumns + `
+		FROM campaigns
+		ORDER BY starts_at DESC
+	`
+
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.fetchCampaigns(rows)
+}
+
+// GetRunningCampaigns returns active campaigns of the program whose time
+// window contains the given moment.
+func (r *CampaignRepository) GetRunningCampaigns(ctx context.Context, tx pgx.Tx, programCode string, at time.Time) (models.CampaignModelList, error) {
+	query := `
+		SELECT ` + campaignColumns + `
+		FROM campaigns
+		WHERE
+			program_code = $1
+			AND active
+			AND starts_at <= $2
+			AND (ends_at IS NULL OR ends_at > $2)
+		ORDER BY starts_at ASC
+	`
+
+	rows, err := tx.Query(ctx, query, programCode, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.fetchCampaigns(rows)
+}
+
+func (r *CampaignRepository) UpdateCampaign(ctx context.Context, tx pgx.Tx, campaign *models.CampaignModel) error {
+	query := `
+		UPDATE campaigns
+		SET
+			name = $1,
+			program_code = $2,
+			starts_at = $3,
+			ends_at = $4,
+			active = $5,
+			eligibility = $6,
+			bonus = $7,
+			updated_at = $8
+		WHERE uuid = $9
+	`
+
+	res, err := tx.Exec(
+		ctx,
+		query,
+		campaign.Name,
+		campaign.ProgramCode,
+		campaign.StartsAt,
+		campaign.EndsAt,
+		campaign.Active,
+		campaign.Eligibility,
+		campaign.Bonus,
+		campaign.UpdatedAt,
+		campaign.UUID)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() != 1 {
+		return errs.ErrNoRows
+	}
+	return nil
+}
+
+func (r *CampaignRepository) DeleteCampaign(ctx context.Context, tx pgx.Tx, campaignID string) error {
+	query := `DELETE FROM campaigns WHERE uuid = $1`
+
+	res, err := tx.Exec(ctx, query, campaignID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolationCode {
+			return errs.ErrCampaignInUse
+		}
+		return err
+	}
+	if res.RowsAffected() != 1 {
+		return errs.ErrNoRows
+	}
+	return nil
+}
+
+func (r *CampaignRepository) scanCampaign(row pgx.Row) (*models.CampaignModel, error) {
+	var campaign models.CampaignModel
+	err := row.Scan(
+		&campaign.UUID,
+		&campaign.Name,
+		&campaign.ProgramCode,
+		&campaign.StartsAt,
+		&campaign.EndsAt,
+		&campaign.Active,
+		&campaign.Eligibility,
+		&campaign.Bonus,
+		&campaign.CreatedAt,
+		&campaign.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+func (r *CampaignRepository) fetchCampaigns(rows pgx.Rows) (models.CampaignModelList, error) {
+	var campaigns models.CampaignModelList
+	for rows.Next() {
+		campaign, err := r.scanCampaign(rows)
+		if err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, campaign)
+	}
+
+	return campaigns, nil
+}
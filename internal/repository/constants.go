@@ -1,3 +1,8 @@
 package repository
 
 const pgUniqViolationCode = "23505"
+const pgForeignKeyViolationCode = "23503"
+
+// referralCodeUniqueIndex guards users.referral_code, a violation means the
+// generated code collided rather than the login being taken.
+const referralCodeUniqueIndex = "uniq__users__referral_code"
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/etoneja/go-gophermart/internal/errs"
 	"github.com/etoneja/go-gophermart/internal/models"
@@ -22,15 +23,38 @@ type GetOrderOptions struct {
 	SkipLocked    bool
 }
 
-func (r *OrderRepository) GetOrder(ctx context.Context, tx pgx.Tx, opts GetOrderOptions) (*models.OrderModel, error) {
-	query := `
-		SELECT
+// ClaimOrdersOptions describes a sync lease. OrderID restricts the claim to a
+// single order and Lane to the orders of one sync lane, orders uploaded
+// before StaleBefore being stale.
+type ClaimOrdersOptions struct {
+	WorkerID    string
+	OrderID     string
+	Lane        models.SyncLane
+	StaleBefore time.Time
+	Now         time.Time
+	Until       time.Time
+	Limit       int
+}
+
+const orderColumns = `
 			id,
 			user_id,
+			program_code,
 			status,
 			accrual,
+			sync_attempts,
+			next_sync_at,
+			last_sync_error,
+			dead_lettered_at,
+			claimed_by,
+			claimed_until,
 			created_at,
 			updated_at
+`
+
+func (r *OrderRepository) GetOrder(ctx context.Context, tx pgx.Tx, opts GetOrderOptions) (*models.OrderModel, error) {
+	query := `
+		SELECT ` + orderColumns + `
 		FROM orders
 		WHERE id = $1
 	`
@@ -43,14 +67,7 @@ func (r *OrderRepository) GetOrder(ctx context.Context, tx pgx.Tx, opts GetOrder
 		}
 	}
 
-	var order models.OrderModel
-	err := tx.QueryRow(ctx, query, opts.ID).Scan(
-		&order.ID,
-		&order.UserID,
-		&order.Status,
-		&order.Accrual,
-		&order.CreatedAt,
-		&order.UpdatedAt)
+	order, err := r.scanOrder(tx.QueryRow(ctx, query, opts.ID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errs.ErrNoRows
@@ -58,7 +75,7 @@ func (r *OrderRepository) GetOrder(ctx context.Context, tx pgx.Tx, opts GetOrder
 		return nil, err
 	}
 
-	return &order, nil
+	return order, nil
 }
 
 func (r *OrderRepository) CreateOrder(ctx context.Context, tx pgx.Tx, order *models.OrderModel) error {
@@ -66,12 +83,13 @@ func (r *OrderRepository) CreateOrder(ctx context.Context, tx pgx.Tx, order *mod
 		INSERT INTO orders (
 			id,
 			user_id,
+			program_code,
 			status,
 			accrual,
 			created_at,
 			updated_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id
 	`
 
@@ -80,6 +98,7 @@ func (r *OrderRepository) CreateOrder(ctx context.Context, tx pgx.Tx, order *mod
 		query,
 		order.ID,
 		order.UserID,
+		order.ProgramCode,
 		order.Status,
 		order.Accrual,
 		order.CreatedAt,
@@ -95,27 +114,130 @@ func (r *OrderRepository) CreateOrder(ctx context.Context, tx pgx.Tx, order *mod
 	return nil
 }
 
-func (r *OrderRepository) GetOrdersToSync(ctx context.Context, tx pgx.Tx, limit int) (models.OrderModelList, error) {
+// ClaimOrdersToSync leases non-terminal orders that are due for a sync, have
+// not been dead-lettered and are not leased by another worker. The lease is
+// committed with tx, so other workers skip the orders while the accrual
+// system is queried without any transaction open.
+func (r *OrderRepository) ClaimOrdersToSync(ctx context.Context, tx pgx.Tx, opts ClaimOrdersOptions) (models.OrderModelList, error) {
+	orderID, err := orderIDParam(opts.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []any{
+		opts.WorkerID,
+		opts.Until,
+		models.TerminatedOrderStatuses,
+		opts.Now,
+		orderID,
+		opts.Limit,
+	}
+
+	laneCondition := ""
+	if opts.Lane != "" {
+		var laneArgs []any
+		laneCondition, laneArgs = syncLaneCondition(opts.Lane, opts.StaleBefore)
+		args = append(args, laneArgs...)
+	}
+
 	query := `
-		SELECT
-			id,
-			user_id,
-			status,
-			accrual,
-			created_at,
-			updated_at
+		UPDATE orders
+		SET
+			claimed_by = $1,
+			claimed_until = $2
+		WHERE id IN (
+			SELECT id
+			FROM orders
+			WHERE
+				status != all($3)
+				AND dead_lettered_at IS NULL
+				AND (next_sync_at IS NULL OR next_sync_at <= $4)
+				AND (claimed_until IS NULL OR claimed_until <= $4)
+				AND ($5::BIGINT IS NULL OR id = $5)
+				` + laneCondition + `
+			ORDER BY COALESCE(next_sync_at, updated_at) asc
+			FOR UPDATE SKIP LOCKED
+			LIMIT $6
+		)
+		RETURNING ` + orderColumns
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.fetchOrders(rows)
+}
+
+// syncLaneCondition mirrors OrderModel.SyncLane for ClaimOrdersToSync, its
+// arguments start at $7.
+func syncLaneCondition(lane models.SyncLane, staleBefore time.Time) (string, []any) {
+	switch lane {
+	case models.SyncLaneFresh:
+		return "AND created_at > $7 AND status = $8", []any{staleBefore, models.OrderStatusNew}
+	case models.SyncLaneRecheck:
+		return "AND created_at > $7 AND status != $8", []any{staleBefore, models.OrderStatusNew}
+	default:
+		return "AND created_at <= $7", []any{staleBefore}
+	}
+}
+
+// GetNextSyncAt returns the earliest sync scheduled after now, nil when no
+// order is waiting for one.
+func (r *OrderRepository) GetNextSyncAt(ctx context.Context, tx pgx.Tx, now time.Time) (*time.Time, error) {
+	query := `
+		SELECT MIN(next_sync_at)
+		FROM orders
+		WHERE
+			status != all($1)
+			AND dead_lettered_at IS NULL
+			AND next_sync_at > $2
+	`
+
+	var nextSyncAt *time.Time
+	err := tx.QueryRow(ctx, query, models.TerminatedOrderStatuses, now).Scan(&nextSyncAt)
+	if err != nil {
+		return nil, err
+	}
+	return nextSyncAt, nil
+}
+
+// CountOrdersBacklog returns the number of non-terminal orders by status.
+func (r *OrderRepository) CountOrdersBacklog(ctx context.Context, tx pgx.Tx) (map[models.OrderStatus]int64, error) {
+	query := `
+		SELECT status, COUNT(*)
 		FROM orders
 		WHERE status != all($1)
-		ORDER BY updated_at asc
-		FOR UPDATE SKIP LOCKED
-		LIMIT $2
+		GROUP BY status
 	`
 
-	rows, err := tx.Query(
-		ctx,
-		query,
-		models.TerminatedOrderStatuses,
-		limit)
+	rows, err := tx.Query(ctx, query, models.TerminatedOrderStatuses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	backlog := make(map[models.OrderStatus]int64)
+	for rows.Next() {
+		var status models.OrderStatus
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		backlog[status] = count
+	}
+	return backlog, rows.Err()
+}
+
+func (r *OrderRepository) GetDeadLetterOrders(ctx context.Context, tx pgx.Tx) (models.OrderModelList, error) {
+	query := `
+		SELECT ` + orderColumns + `
+		FROM orders
+		WHERE dead_lettered_at IS NOT NULL
+		ORDER BY dead_lettered_at DESC
+	`
+
+	rows, err := tx.Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -125,13 +247,7 @@ func (r *OrderRepository) GetOrdersToSync(ctx context.Context, tx pgx.Tx, limit
 
 func (r *OrderRepository) GetOrdersForUserID(ctx context.Context, tx pgx.Tx, userID string) (models.OrderModelList, error) {
 	query := `
-		SELECT
-			id,
-			user_id,
-			status,
-			accrual,
-			created_at,
-			updated_at
+		SELECT ` + orderColumns + `
 		FROM orders
 		WHERE user_id = $1
 		ORDER BY created_at asc
@@ -146,21 +262,36 @@ func (r *OrderRepository) GetOrdersForUserID(ctx context.Context, tx pgx.Tx, use
 
 }
 
+func (r *OrderRepository) scanOrder(row pgx.Row) (*models.OrderModel, error) {
+	var order models.OrderModel
+	err := row.Scan(
+		&order.ID,
+		&order.UserID,
+		&order.ProgramCode,
+		&order.Status,
+		&order.Accrual,
+		&order.SyncAttempts,
+		&order.NextSyncAt,
+		&order.LastSyncError,
+		&order.DeadLetteredAt,
+		&order.ClaimedBy,
+		&order.ClaimedUntil,
+		&order.CreatedAt,
+		&order.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
 func (r *OrderRepository) fetchOrders(rows pgx.Rows) (models.OrderModelList, error) {
 	var orders models.OrderModelList
 	for rows.Next() {
-		var order models.OrderModel
-		if err := rows.Scan(
-			&order.ID,
-			&order.UserID,
-			&order.Status,
-			&order.Accrual,
-			&order.CreatedAt,
-			&order.UpdatedAt,
-		); err != nil {
+		order, err := r.scanOrder(rows)
+		if err != nil {
 			return nil, err
 		}
-		orders = append(orders, &order)
+		orders = append(orders, order)
 	}
 
 	return orders, nil
@@ -172,8 +303,14 @@ func (r *OrderRepository) UpdateOrder(ctx context.Context, tx pgx.Tx, order *mod
 		SET
 			status = $1,
 			accrual = $2,
-			updated_at = $3
-		WHERE id = $4
+			sync_attempts = $3,
+			next_sync_at = $4,
+			last_sync_error = $5,
+			dead_lettered_at = $6,
+			claimed_by = $7,
+			claimed_until = $8,
+			updated_at = $9
+		WHERE id = $10
 	`
 
 	res, err := tx.Exec(
@@ -181,6 +318,12 @@ func (r *OrderRepository) UpdateOrder(ctx context.Context, tx pgx.Tx, order *mod
 		query,
 		order.Status,
 		order.Accrual,
+		order.SyncAttempts,
+		order.NextSyncAt,
+		order.LastSyncError,
+		order.DeadLetteredAt,
+		order.ClaimedBy,
+		order.ClaimedUntil,
 		order.UpdatedAt,
 		order.ID)
 	if err != nil {
@@ -191,3 +334,22 @@ func (r *OrderRepository) UpdateOrder(ctx context.Context, tx pgx.Tx, order *mod
 	}
 	return nil
 }
+
+// HasOtherProcessedOrders reports whether the user has processed orders other
+// than the given one.
+func (r *OrderRepository) HasOtherProcessedOrders(ctx context.Context, tx pgx.Tx, userID, orderID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM orders
+			WHERE user_id = $1 AND id != $2 AND status = $3
+		)
+	`
+
+	var exists bool
+	err := tx.QueryRow(ctx, query, userID, orderID, models.OrderStatusProcessed).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
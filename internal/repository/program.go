@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/etoneja/go-gophermart/internal/errs"
+	"github.com/etoneja/go-gophermart/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+type ProgramRepository struct{}
+
+func NewProgramRepository() *ProgramRepository {
+	return &ProgramRepository{}
+}
+
+func (r *ProgramRepository) GetProgram(ctx context.Context, tx pgx.Tx, code string) (*models.ProgramModel, error) {
+	query := `
+		SELECT
+			code,
+			name,
+			accrual_rate,
+			conversion_rate,
+			created_at
+		FROM programs
+		WHERE code = $1
+	`
+
+	var program models.ProgramModel
+	err := tx.QueryRow(ctx, query, code).Scan(
+		&program.Code,
+		&program.Name,
+		&program.AccrualRate,
+		&program.ConversionRate,
+		&program.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &program, nil
+}
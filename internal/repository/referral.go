@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/errs"
+	"github.com/etoneja/go-gophermart/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+type ReferralRepository struct{}
+
+func NewReferralRepository() *ReferralRepository {
+	return &ReferralRepository{}
+}
+
+type GetReferralOptions struct {
+	RefereeID     string
+	LockForUpdate bool
+}
+
+func (r *ReferralRepository) CreateReferral(ctx context.Context, tx pgx.Tx, referral *models.ReferralModel) error {
+	query := `
+		INSERT INTO referrals (
+			referee_id,
+			referrer_id,
+			status,
+			created_at,
+			rewarded_at
+		)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := tx.Exec(
+		ctx,
+		query,
+		referral.RefereeID,
+		referral.ReferrerID,
+		referral.Status,
+		referral.CreatedAt,
+		referral.RewardedAt)
+
+	return err
+}
+
+func (r *ReferralRepository) GetReferral(ctx context.Context, tx pgx.Tx, opts GetReferralOptions) (*models.ReferralModel, error) {
+	query := `
+		SELECT
+			referee_id,
+			referrer_id,
+			status,
+			created_at,
+			rewarded_at
+		FROM referrals
+		WHERE referee_id = $1
+	`
+
+	if opts.LockForUpdate {
+		query += " FOR UPDATE"
+	}
+
+	var referral models.ReferralModel
+	err := tx.QueryRow(ctx, query, opts.RefereeID).Scan(
+		&referral.RefereeID,
+		&referral.ReferrerID,
+		&referral.Status,
+		&referral.CreatedAt,
+		&referral.RewardedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &referral, nil
+}
+
+func (r *ReferralRepository) UpdateReferral(ctx context.Context, tx pgx.Tx, referral *models.ReferralModel) error {
+	query := `
+		UPDATE referrals
+		SET
+			status = $1,
+			rewarded_at = $2
+		WHERE referee_id = $3
+	`
+
+	res, err := tx.Exec(
+		ctx,
+		query,
+		referral.Status,
+		referral.RewardedAt,
+		referral.RefereeID)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() != 1 {
+		return errs.ErrNotOnlyOneRowAffected
+	}
+	return nil
+}
+
+func (r *ReferralRepository) CountRewardedReferrals(ctx context.Context, tx pgx.Tx, referrerID string, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM referrals
+		WHERE referrer_id = $1 AND status = $2 AND rewarded_at >= $3
+	`
+
+	var count int
+	err := tx.QueryRow(ctx, query, referrerID, models.ReferralStatusRewarded, since).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *ReferralRepository) GetReferralsForReferrer(ctx context.Context, tx pgx.Tx, referrerID string) (models.ReferralModelList, error) {
+	query := `
+		SELECT
+			r.referee_id,
+			u.login,
+			r.referrer_id,
+			r.status,
+			r.created_at,
+			r.rewarded_at
+		FROM referrals AS r
+		JOIN users AS u ON u.uuid = r.referee_id
+		WHERE r.referrer_id = $1
+		ORDER BY r.created_at DESC
+	`
+
+	rows, err := tx.Query(ctx, query, referrerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var referrals models.ReferralModelList
+	for rows.Next() {
+		var referral models.ReferralModel
+		if err := rows.Scan(
+			&referral.RefereeID,
+			&referral.RefereeLogin,
+			&referral.ReferrerID,
+			&referral.Status,
+			&referral.CreatedAt,
+			&referral.RewardedAt,
+		); err != nil {
+			return nil, err
+		}
+		referrals = append(referrals, &referral)
+	}
+
+	return referrals, nil
+}
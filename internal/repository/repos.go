@@ -3,13 +3,25 @@ package repository
 type Repositories struct {
 	OrderRepo       *OrderRepository
 	UserRepo        *UserRepository
+	AccountRepo     *AccountRepository
+	CampaignRepo    *CampaignRepository
+	ProgramRepo     *ProgramRepository
+	ReferralRepo    *ReferralRepository
+	TierRepo        *TierRepository
 	TransactionRepo *TransactionRepository
+	TransferRepo    *TransferRepository
 }
 
 func NewRepositories() *Repositories {
 	return &Repositories{
 		OrderRepo:       NewOrderRepository(),
 		UserRepo:        NewUserRepository(),
+		AccountRepo:     NewAccountRepository(),
+		CampaignRepo:    NewCampaignRepository(),
+		ProgramRepo:     NewProgramRepository(),
+		ReferralRepo:    NewReferralRepository(),
+		TierRepo:        NewTierRepository(),
 		TransactionRepo: NewTransactionRepository(),
+		TransferRepo:    NewTransferRepository(),
 	}
 }
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/errs"
+	"github.com/etoneja/go-gophermart/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+type TierRepository struct{}
+
+func NewTierRepository() *TierRepository {
+	return &TierRepository{}
+}
+
+func (r *TierRepository) GetUserTier(ctx context.Context, tx pgx.Tx, userID string) (*models.UserTierModel, error) {
+	query := `
+		SELECT
+			user_id,
+			tier,
+			accrued,
+			updated_at
+		FROM user_tiers
+		WHERE user_id = $1
+	`
+
+	var tier models.UserTierModel
+	err := tx.QueryRow(ctx, query, userID).Scan(
+		&tier.UserID,
+		&tier.Level,
+		&tier.Accrued,
+		&tier.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &tier, nil
+}
+
+// RecalculateTiers recomputes the tier of every user from the rolling
+// accrual totals since the given time and returns the number of rows written.
+func (r *TierRepository) RecalculateTiers(ctx context.Context, tx pgx.Tx, since time.Time, updatedAt time.Time) (int64, error) {
+	args := []any{models.DefaultProgramCode, models.TransactionTypeAccrual, since, updatedAt}
+
+	var cases []string
+	for i := len(models.Tiers) - 1; i > 0; i-- {
+		cases = append(cases, fmt.Sprintf("WHEN totals.accrued >= $%d THEN $%d", len(args)+1, len(args)+2))
+		args = append(args, models.Tiers[i].Threshold, models.Tiers[i].Level)
+	}
+	args = append(args, models.Tiers[0].Level)
+	tierExpr := fmt.Sprintf("CASE %s ELSE $%d END", strings.Join(cases, " "), len(args))
+
+	query := `
+		INSERT INTO user_tiers (user_id, tier, accrued, updated_at)
+		SELECT
+			totals.user_id,
+			` + tierExpr + `,
+			totals.accrued,
+			$4
+		FROM (
+			SELECT
+				u.uuid AS user_id,
+				COALESCE(SUM(t.amount), 0)::BIGINT AS accrued
+			FROM users AS u
+			LEFT JOIN transactions AS t
+				ON t.user_id = u.uuid
+				AND t.program_code = $1
+				AND t.type = $2
+				AND t.created_at >= $3
+			GROUP BY u.uuid
+		) AS totals
+		ON CONFLICT (user_id) DO UPDATE
+		SET
+			tier = EXCLUDED.tier,
+			accrued = EXCLUDED.accrued,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	res, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected(), nil
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 
 	"github.com/etoneja/go-gophermart/internal/models"
 	"github.com/jackc/pgx/v5"
@@ -18,22 +20,46 @@ func (r *TransactionRepository) CreateTransaction(ctx context.Context, tx pgx.Tx
 		INSERT INTO transactions (
 			uuid,
 			user_id,
+			program_code,
 			order_id,
+			campaign_id,
+			transfer_id,
 			type,
 			amount,
 			created_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
-	_, err := tx.Exec(
+	orderID, err := orderIDParam(transaction.OrderID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
 		ctx,
 		query,
 		transaction.UUID,
 		transaction.UserID,
-		transaction.OrderID,
+		transaction.ProgramCode,
+		orderID,
+		transaction.CampaignID,
+		transaction.TransferID,
 		transaction.Type,
 		transaction.Amount,
 		transaction.CreatedAt)
 
 	return err
 }
+
+// orderIDParam binds an order number to a nullable BIGINT parameter, an empty
+// number binds NULL.
+func orderIDParam(orderID string) (*int64, error) {
+	if orderID == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order number %q: %w", orderID, err)
+	}
+	return &id, nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/errs"
+	"github.com/etoneja/go-gophermart/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+type TransferRepository struct{}
+
+func NewTransferRepository() *TransferRepository {
+	return &TransferRepository{}
+}
+
+func (r *TransferRepository) CreateTransfer(ctx context.Context, tx pgx.Tx, transfer *models.TransferModel) error {
+	query := `
+		INSERT INTO transfers (
+			uuid,
+			sender_id,
+			recipient_id,
+			program_code,
+			amount,
+			idempotency_key,
+			created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := tx.Exec(
+		ctx,
+		query,
+		transfer.UUID,
+		transfer.SenderID,
+		transfer.RecipientID,
+		transfer.ProgramCode,
+		transfer.Amount,
+		transfer.IdempotencyKey,
+		transfer.CreatedAt)
+
+	return err
+}
+
+func (r *TransferRepository) GetTransferByIdempotencyKey(ctx context.Context, tx pgx.Tx, senderID, idempotencyKey string) (*models.TransferModel, error) {
+	query := `
+		SELECT
+			t.uuid,
+			t.sender_id,
+			t.recipient_id,
+			u.login,
+			t.program_code,
+			t.amount,
+			t.idempotency_key,
+			t.created_at
+		FROM transfers AS t
+		JOIN users AS u ON u.uuid = t.recipient_id
+		WHERE t.sender_id = $1 AND t.idempotency_key = $2
+	`
+
+	var transfer models.TransferModel
+	err := tx.QueryRow(ctx, query, senderID, idempotencyKey).Scan(
+		&transfer.UUID,
+		&transfer.SenderID,
+		&transfer.RecipientID,
+		&transfer.RecipientLogin,
+		&transfer.ProgramCode,
+		&transfer.Amount,
+		&transfer.IdempotencyKey,
+		&transfer.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &transfer, nil
+}
+
+func (r *TransferRepository) SumTransfersSince(ctx context.Context, tx pgx.Tx, senderID, programCode string, since time.Time) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM transfers
+		WHERE sender_id = $1 AND program_code = $2 AND created_at >= $3
+	`
+
+	var sum int64
+	err := tx.QueryRow(ctx, query, senderID, programCode, since).Scan(&sum)
+	if err != nil {
+		return 0, err
+	}
+	return sum, nil
+}
@@ -21,6 +21,7 @@ func NewUserRepository() *UserRepository {
 type GetUserOptions struct {
 	UUID          string
 	Login         string
+	ReferralCode  string
 	LockForUpdate bool
 }
 
@@ -30,7 +31,7 @@ func (r *UserRepository) CreateUser(ctx context.Context, tx pgx.Tx, user *models
 			uuid,
 			login,
 			hashed_password,
-			balance,
+			referral_code,
 			created_at
 		)
         VALUES ($1, $2, $3, $4, $5)
@@ -42,12 +43,15 @@ func (r *UserRepository) CreateUser(ctx context.Context, tx pgx.Tx, user *models
 		user.UUID,
 		user.Login,
 		user.HashedPassword,
-		user.Balance,
+		user.ReferralCode,
 		user.CreatedAt)
 
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == pgUniqViolationCode {
+			if pgErr.ConstraintName == referralCodeUniqueIndex {
+				return errs.ErrReferralCodeTaken
+			}
 			return errs.ErrUserExists
 		}
 		return err
@@ -65,7 +69,7 @@ func (r *UserRepository) GetUser(ctx context.Context, tx pgx.Tx, opts GetUserOpt
 			uuid,
 			login,
 			hashed_password,
-			balance,
+			referral_code,
 			created_at
         FROM users
         WHERE 
@@ -83,6 +87,11 @@ func (r *UserRepository) GetUser(ctx context.Context, tx pgx.Tx, opts GetUserOpt
 		args = append(args, opts.Login)
 	}
 
+	if opts.ReferralCode != "" {
+		conditions = append(conditions, fmt.Sprintf("referral_code = $%d", len(args)+1))
+		args = append(args, opts.ReferralCode)
+	}
+
 	if len(conditions) == 0 {
 		return nil, fmt.Errorf("no search criteria provided")
 	}
@@ -98,7 +107,7 @@ func (r *UserRepository) GetUser(ctx context.Context, tx pgx.Tx, opts GetUserOpt
 		&user.UUID,
 		&user.Login,
 		&user.HashedPassword,
-		&user.Balance,
+		&user.ReferralCode,
 		&user.CreatedAt)
 
 	if err != nil {
@@ -108,69 +117,23 @@ func (r *UserRepository) GetUser(ctx context.Context, tx pgx.Tx, opts GetUserOpt
 	return &user, nil
 }
 
-func (r *UserRepository) GetUserBalance(ctx context.Context, tx pgx.Tx, userID string) (*models.BalanceModel, error) {
-	query := `
-		SELECT 
-			u.balance,
-			COALESCE(
-				(SELECT SUM(amount) 
-				FROM transactions 
-				WHERE user_id = $1 AND type = $2), 
-				0
-			) AS withdrawn
-		FROM users as u
-		WHERE u.uuid = $1;
-    `
-
-	var balance models.BalanceModel
-	err := tx.QueryRow(ctx, query, userID, models.TransactionTypeWithdraw).Scan(
-		&balance.Current,
-		&balance.Withdrawn)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return &balance, nil
-}
-
-func (r *UserRepository) UpdateUserBalance(ctx context.Context, tx pgx.Tx, transaction *models.TransactionModel) error {
-	query := `
-		UPDATE users 
-		SET balance = balance + $1
-		WHERE uuid = $2
-	`
-
-	res, err := tx.Exec(
-		ctx,
-		query,
-		transaction.SignedAmount(),
-		transaction.UserID)
-
-	if err != nil {
-		return fmt.Errorf("failed to update balance: %w", err)
-	}
-	if res.RowsAffected() != 1 {
-		return errs.ErrNotOnlyOneRowAffected
-	}
-	return nil
-}
-
-func (r *UserRepository) GetUserWithdrawals(ctx context.Context, tx pgx.Tx, userID string) ([]*models.WithdrawModel, error) {
+func (r *UserRepository) GetUserWithdrawals(ctx context.Context, tx pgx.Tx, userID, programCode string) ([]*models.WithdrawModel, error) {
 	query := `
 		SELECT
 			user_id,
+			program_code,
 			order_id,
 			amount,
 			created_at
 		FROM transactions as t
 		WHERE
 			t.user_id = $1
+			and t.program_code = $2
 			and t.type = 'withdraw'
 		ORDER BY created_at DESC;
     `
 
-	rows, err := tx.Query(ctx, query, userID)
+	rows, err := tx.Query(ctx, query, userID, programCode)
 	if err != nil {
 		return nil, err
 	}
@@ -181,6 +144,7 @@ func (r *UserRepository) GetUserWithdrawals(ctx context.Context, tx pgx.Tx, user
 		var withdraw models.WithdrawModel
 		if err := rows.Scan(
 			&withdraw.UserID,
+			&withdraw.ProgramCode,
 			&withdraw.OrderID,
 			&withdraw.Sum,
 			&withdraw.CreatedAt,
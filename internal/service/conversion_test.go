@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/etoneja/go-gophermart/internal/errs"
+	"github.com/etoneja/go-gophermart/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ConvertBalance(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	// One mile is worth four default program points.
+	_, err := s.dbPool.Exec(ctx, `
+		INSERT INTO programs (code, name, accrual_rate, conversion_rate)
+		VALUES ('miles', 'Partner miles', 2500, 40000)
+		ON CONFLICT (code) DO UPDATE SET conversion_rate = EXCLUDED.conversion_rate
+	`)
+	require.NoError(t, err)
+
+	order := newTestOrder(t, s, "converter", "12345678903")
+	accrual := int64(10000)
+	require.NoError(t, s.ApplyAccrualUpdate(ctx, &models.AccrualOrderModel{
+		ID:      order.ID,
+		Status:  models.AccrualOrderStatusProcessed,
+		Accrual: &accrual,
+	}))
+
+	convert := func(from, to string, amount int64) (*models.ConversionModel, error) {
+		conversion := &models.ConversionModel{
+			UserID:          order.UserID,
+			FromProgramCode: from,
+			ToProgramCode:   to,
+			Amount:          amount,
+		}
+		return conversion, s.ConvertBalance(ctx, conversion)
+	}
+
+	conversion, err := convert(models.DefaultProgramCode, "miles", 4002)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), conversion.Converted, "rounded down")
+
+	_, err = convert(models.DefaultProgramCode, "miles", 3)
+	assert.ErrorIs(t, err, errs.ErrConversionTooSmall)
+	_, err = convert(models.DefaultProgramCode, "miles", 10000)
+	assert.ErrorIs(t, err, errs.ErrInsufficientFunds)
+	_, err = convert("miles", "miles", 1)
+	assert.ErrorIs(t, err, errs.ErrSameProgram)
+	_, err = convert(models.DefaultProgramCode, "unknown", 1)
+	assert.ErrorIs(t, err, errs.ErrProgramNotFound)
+
+	balances := make(map[string]int64)
+	list, err := s.GetUserBalances(ctx, order.UserID)
+	require.NoError(t, err)
+	for _, balance := range list {
+		balances[balance.ProgramCode] = balance.Current
+	}
+	assert.Equal(t, map[string]int64{models.DefaultProgramCode: 5998, "miles": 1000}, balances)
+}
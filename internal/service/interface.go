@@ -2,22 +2,44 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/etoneja/go-gophermart/internal/models"
 )
 
 type Servicer interface {
 	IsAccrualSytemBusy() bool
-	RegisterUser(ctx context.Context, login, password string) (*models.UserModel, string, error)
+	PingAccrualSystem(ctx context.Context) error
+	RegisterUser(ctx context.Context, login, password, referralCode string) (*models.UserModel, string, error)
 	LoginUser(ctx context.Context, login, password string) (*models.UserModel, string, error)
 	ValidateToken(tokenString string) (string, error)
 	GetUserByLogin(ctx context.Context, login string) (*models.UserModel, error)
-	GetUserBalance(ctx context.Context, userID string) (*models.BalanceModel, error)
+	GetUserBalance(ctx context.Context, userID, programCode string) (*models.BalanceModel, error)
+	GetUserBalances(ctx context.Context, userID string) (models.BalanceModelList, error)
 	CreateOrGetOrder(ctx context.Context, order *models.OrderModel) (*models.OrderModel, error)
 	GetOrdersForUser(ctx context.Context, user *models.UserModel) (models.OrderModelList, error)
-	GetOrdersToSync(ctx context.Context, limit int) (models.OrderModelList, error)
+	ClaimOrdersToSync(ctx context.Context, limit int) (models.OrderModelList, error)
+	EnsureOrdersSync(ctx context.Context) error
+	GetOrdersBacklog(ctx context.Context) (map[models.OrderStatus]int64, error)
+	GetNextSyncAt(ctx context.Context) (*time.Time, error)
 	GetOrder(ctx context.Context, orderID string) (*models.OrderModel, error)
-	GetUserWithdrawals(ctx context.Context, userID string) (models.WithdrawModelList, error)
+	GetDeadLetterOrders(ctx context.Context) (models.OrderModelList, error)
+	RetryOrder(ctx context.Context, orderID string) (*models.OrderModel, error)
+	GetUserWithdrawals(ctx context.Context, userID, programCode string) (models.WithdrawModelList, error)
 	CreateWithdraw(ctx context.Context, withdraw *models.WithdrawModel) error
+	CreateTransfer(ctx context.Context, transfer *models.TransferModel) (*models.TransferModel, error)
+	ConvertBalance(ctx context.Context, conversion *models.ConversionModel) error
 	SyncOrder(ctx context.Context, orderID string) error
+	SyncOrders(ctx context.Context, orderIDs []string) (SyncReport, error)
+	SyncBatchSize(ctx context.Context) int
+	ApplyAccrualUpdate(ctx context.Context, accrualOrder *models.AccrualOrderModel) error
+	GetUserReferrals(ctx context.Context, userID string) (models.ReferralModelList, error)
+	GetUserTier(ctx context.Context, userID string) (*models.UserTierModel, error)
+	RecalculateTiers(ctx context.Context) (int64, error)
+	EnsureTiersRecalc(ctx context.Context) error
+	CreateCampaign(ctx context.Context, campaign *models.CampaignModel) error
+	GetCampaign(ctx context.Context, campaignID string) (*models.CampaignModel, error)
+	GetCampaigns(ctx context.Context) (models.CampaignModelList, error)
+	UpdateCampaign(ctx context.Context, campaign *models.CampaignModel) error
+	DeleteCampaign(ctx context.Context, campaignID string) error
 }
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/etoneja/go-gophermart/internal/models"
+)
+
+// laneScheduler splits order claims between the sync lanes by weight using
+// smooth weighted round-robin. Credit carries over between claims, so every
+// lane with a positive weight gets its share over time even when each claim
+// is for a single order, and a backlog in one lane can't starve the others.
+type laneScheduler struct {
+	mu      sync.Mutex
+	weights map[models.SyncLane]int
+	total   int
+	credit  map[models.SyncLane]int
+}
+
+func newLaneScheduler(weights map[models.SyncLane]int) *laneScheduler {
+	ls := &laneScheduler{
+		weights: make(map[models.SyncLane]int),
+		credit:  make(map[models.SyncLane]int),
+	}
+	for _, lane := range models.SyncLanes {
+		if weight := weights[lane]; weight > 0 {
+			ls.weights[lane] = weight
+			ls.total += weight
+		}
+	}
+	return ls
+}
+
+// allocate returns how many of n slots go to each lane.
+func (ls *laneScheduler) allocate(n int) map[models.SyncLane]int {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	shares := make(map[models.SyncLane]int)
+	if ls.total == 0 {
+		return shares
+	}
+
+	for range n {
+		var best models.SyncLane
+		for _, lane := range models.SyncLanes {
+			weight, ok := ls.weights[lane]
+			if !ok {
+				continue
+			}
+			ls.credit[lane] += weight
+			if best == "" || ls.credit[lane] > ls.credit[best] {
+				best = lane
+			}
+		}
+		ls.credit[best] -= ls.total
+		shares[best]++
+	}
+	return shares
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/etoneja/go-gophermart/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLaneScheduler_Allocate(t *testing.T) {
+	ls := newLaneScheduler(map[models.SyncLane]int{
+		models.SyncLaneFresh:   6,
+		models.SyncLaneRecheck: 3,
+		models.SyncLaneStale:   1,
+	})
+
+	assert.Equal(t, map[models.SyncLane]int{
+		models.SyncLaneFresh:   6,
+		models.SyncLaneRecheck: 3,
+		models.SyncLaneStale:   1,
+	}, ls.allocate(10))
+}
+
+func TestLaneScheduler_SingleSlotClaimsDoNotStarve(t *testing.T) {
+	ls := newLaneScheduler(map[models.SyncLane]int{
+		models.SyncLaneFresh:   6,
+		models.SyncLaneRecheck: 3,
+		models.SyncLaneStale:   1,
+	})
+
+	total := make(map[models.SyncLane]int)
+	for range 10 {
+		shares := ls.allocate(1)
+		assert.Len(t, shares, 1)
+		for lane, n := range shares {
+			total[lane] += n
+		}
+	}
+
+	assert.Equal(t, 6, total[models.SyncLaneFresh])
+	assert.Equal(t, 3, total[models.SyncLaneRecheck])
+	assert.Equal(t, 1, total[models.SyncLaneStale])
+}
+
+func TestLaneScheduler_FreshFirst(t *testing.T) {
+	ls := newLaneScheduler(map[models.SyncLane]int{
+		models.SyncLaneFresh:   1,
+		models.SyncLaneRecheck: 1,
+		models.SyncLaneStale:   1,
+	})
+
+	assert.Equal(t, map[models.SyncLane]int{models.SyncLaneFresh: 1}, ls.allocate(1))
+}
+
+func TestLaneScheduler_NoWeights(t *testing.T) {
+	ls := newLaneScheduler(nil)
+	assert.Empty(t, ls.allocate(5))
+}
@@ -7,8 +7,10 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/etoneja/go-gophermart/internal/models"
+	service "github.com/etoneja/go-gophermart/internal/service"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -35,6 +37,63 @@ func (m *MockServicer) EXPECT() *MockServicerMockRecorder {
 	return m.recorder
 }
 
+// ApplyAccrualUpdate mocks base method.
+func (m *MockServicer) ApplyAccrualUpdate(ctx context.Context, accrualOrder *models.AccrualOrderModel) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyAccrualUpdate", ctx, accrualOrder)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyAccrualUpdate indicates an expected call of ApplyAccrualUpdate.
+func (mr *MockServicerMockRecorder) ApplyAccrualUpdate(ctx, accrualOrder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyAccrualUpdate", reflect.TypeOf((*MockServicer)(nil).ApplyAccrualUpdate), ctx, accrualOrder)
+}
+
+// ClaimOrdersToSync mocks base method.
+func (m *MockServicer) ClaimOrdersToSync(ctx context.Context, limit int) (models.OrderModelList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimOrdersToSync", ctx, limit)
+	ret0, _ := ret[0].(models.OrderModelList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimOrdersToSync indicates an expected call of ClaimOrdersToSync.
+func (mr *MockServicerMockRecorder) ClaimOrdersToSync(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimOrdersToSync", reflect.TypeOf((*MockServicer)(nil).ClaimOrdersToSync), ctx, limit)
+}
+
+// ConvertBalance mocks base method.
+func (m *MockServicer) ConvertBalance(ctx context.Context, conversion *models.ConversionModel) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConvertBalance", ctx, conversion)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConvertBalance indicates an expected call of ConvertBalance.
+func (mr *MockServicerMockRecorder) ConvertBalance(ctx, conversion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConvertBalance", reflect.TypeOf((*MockServicer)(nil).ConvertBalance), ctx, conversion)
+}
+
+// CreateCampaign mocks base method.
+func (m *MockServicer) CreateCampaign(ctx context.Context, campaign *models.CampaignModel) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCampaign", ctx, campaign)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCampaign indicates an expected call of CreateCampaign.
+func (mr *MockServicerMockRecorder) CreateCampaign(ctx, campaign interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCampaign", reflect.TypeOf((*MockServicer)(nil).CreateCampaign), ctx, campaign)
+}
+
 // CreateOrGetOrder mocks base method.
 func (m *MockServicer) CreateOrGetOrder(ctx context.Context, order *models.OrderModel) (*models.OrderModel, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +109,21 @@ func (mr *MockServicerMockRecorder) CreateOrGetOrder(ctx, order interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrGetOrder", reflect.TypeOf((*MockServicer)(nil).CreateOrGetOrder), ctx, order)
 }
 
+// CreateTransfer mocks base method.
+func (m *MockServicer) CreateTransfer(ctx context.Context, transfer *models.TransferModel) (*models.TransferModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", ctx, transfer)
+	ret0, _ := ret[0].(*models.TransferModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockServicerMockRecorder) CreateTransfer(ctx, transfer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockServicer)(nil).CreateTransfer), ctx, transfer)
+}
+
 // CreateWithdraw mocks base method.
 func (m *MockServicer) CreateWithdraw(ctx context.Context, withdraw *models.WithdrawModel) error {
 	m.ctrl.T.Helper()
@@ -64,6 +138,108 @@ func (mr *MockServicerMockRecorder) CreateWithdraw(ctx, withdraw interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithdraw", reflect.TypeOf((*MockServicer)(nil).CreateWithdraw), ctx, withdraw)
 }
 
+// DeleteCampaign mocks base method.
+func (m *MockServicer) DeleteCampaign(ctx context.Context, campaignID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCampaign", ctx, campaignID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCampaign indicates an expected call of DeleteCampaign.
+func (mr *MockServicerMockRecorder) DeleteCampaign(ctx, campaignID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCampaign", reflect.TypeOf((*MockServicer)(nil).DeleteCampaign), ctx, campaignID)
+}
+
+// EnsureOrdersSync mocks base method.
+func (m *MockServicer) EnsureOrdersSync(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureOrdersSync", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureOrdersSync indicates an expected call of EnsureOrdersSync.
+func (mr *MockServicerMockRecorder) EnsureOrdersSync(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureOrdersSync", reflect.TypeOf((*MockServicer)(nil).EnsureOrdersSync), ctx)
+}
+
+// EnsureTiersRecalc mocks base method.
+func (m *MockServicer) EnsureTiersRecalc(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureTiersRecalc", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureTiersRecalc indicates an expected call of EnsureTiersRecalc.
+func (mr *MockServicerMockRecorder) EnsureTiersRecalc(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureTiersRecalc", reflect.TypeOf((*MockServicer)(nil).EnsureTiersRecalc), ctx)
+}
+
+// GetCampaign mocks base method.
+func (m *MockServicer) GetCampaign(ctx context.Context, campaignID string) (*models.CampaignModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCampaign", ctx, campaignID)
+	ret0, _ := ret[0].(*models.CampaignModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCampaign indicates an expected call of GetCampaign.
+func (mr *MockServicerMockRecorder) GetCampaign(ctx, campaignID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCampaign", reflect.TypeOf((*MockServicer)(nil).GetCampaign), ctx, campaignID)
+}
+
+// GetCampaigns mocks base method.
+func (m *MockServicer) GetCampaigns(ctx context.Context) (models.CampaignModelList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCampaigns", ctx)
+	ret0, _ := ret[0].(models.CampaignModelList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCampaigns indicates an expected call of GetCampaigns.
+func (mr *MockServicerMockRecorder) GetCampaigns(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCampaigns", reflect.TypeOf((*MockServicer)(nil).GetCampaigns), ctx)
+}
+
+// GetDeadLetterOrders mocks base method.
+func (m *MockServicer) GetDeadLetterOrders(ctx context.Context) (models.OrderModelList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetterOrders", ctx)
+	ret0, _ := ret[0].(models.OrderModelList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetterOrders indicates an expected call of GetDeadLetterOrders.
+func (mr *MockServicerMockRecorder) GetDeadLetterOrders(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetterOrders", reflect.TypeOf((*MockServicer)(nil).GetDeadLetterOrders), ctx)
+}
+
+// GetNextSyncAt mocks base method.
+func (m *MockServicer) GetNextSyncAt(ctx context.Context) (*time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNextSyncAt", ctx)
+	ret0, _ := ret[0].(*time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNextSyncAt indicates an expected call of GetNextSyncAt.
+func (mr *MockServicerMockRecorder) GetNextSyncAt(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNextSyncAt", reflect.TypeOf((*MockServicer)(nil).GetNextSyncAt), ctx)
+}
+
 // GetOrder mocks base method.
 func (m *MockServicer) GetOrder(ctx context.Context, orderID string) (*models.OrderModel, error) {
 	m.ctrl.T.Helper()
@@ -79,6 +255,21 @@ func (mr *MockServicerMockRecorder) GetOrder(ctx, orderID interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockServicer)(nil).GetOrder), ctx, orderID)
 }
 
+// GetOrdersBacklog mocks base method.
+func (m *MockServicer) GetOrdersBacklog(ctx context.Context) (map[models.OrderStatus]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrdersBacklog", ctx)
+	ret0, _ := ret[0].(map[models.OrderStatus]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrdersBacklog indicates an expected call of GetOrdersBacklog.
+func (mr *MockServicerMockRecorder) GetOrdersBacklog(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersBacklog", reflect.TypeOf((*MockServicer)(nil).GetOrdersBacklog), ctx)
+}
+
 // GetOrdersForUser mocks base method.
 func (m *MockServicer) GetOrdersForUser(ctx context.Context, user *models.UserModel) (models.OrderModelList, error) {
 	m.ctrl.T.Helper()
@@ -94,34 +285,34 @@ func (mr *MockServicerMockRecorder) GetOrdersForUser(ctx, user interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersForUser", reflect.TypeOf((*MockServicer)(nil).GetOrdersForUser), ctx, user)
 }
 
-// GetOrdersToSync mocks base method.
-func (m *MockServicer) GetOrdersToSync(ctx context.Context, limit int) (models.OrderModelList, error) {
+// GetUserBalance mocks base method.
+func (m *MockServicer) GetUserBalance(ctx context.Context, userID, programCode string) (*models.BalanceModel, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrdersToSync", ctx, limit)
-	ret0, _ := ret[0].(models.OrderModelList)
+	ret := m.ctrl.Call(m, "GetUserBalance", ctx, userID, programCode)
+	ret0, _ := ret[0].(*models.BalanceModel)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetOrdersToSync indicates an expected call of GetOrdersToSync.
-func (mr *MockServicerMockRecorder) GetOrdersToSync(ctx, limit interface{}) *gomock.Call {
+// GetUserBalance indicates an expected call of GetUserBalance.
+func (mr *MockServicerMockRecorder) GetUserBalance(ctx, userID, programCode interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersToSync", reflect.TypeOf((*MockServicer)(nil).GetOrdersToSync), ctx, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserBalance", reflect.TypeOf((*MockServicer)(nil).GetUserBalance), ctx, userID, programCode)
 }
 
-// GetUserBalance mocks base method.
-func (m *MockServicer) GetUserBalance(ctx context.Context, userID string) (*models.BalanceModel, error) {
+// GetUserBalances mocks base method.
+func (m *MockServicer) GetUserBalances(ctx context.Context, userID string) (models.BalanceModelList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserBalance", ctx, userID)
-	ret0, _ := ret[0].(*models.BalanceModel)
+	ret := m.ctrl.Call(m, "GetUserBalances", ctx, userID)
+	ret0, _ := ret[0].(models.BalanceModelList)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserBalance indicates an expected call of GetUserBalance.
-func (mr *MockServicerMockRecorder) GetUserBalance(ctx, userID interface{}) *gomock.Call {
+// GetUserBalances indicates an expected call of GetUserBalances.
+func (mr *MockServicerMockRecorder) GetUserBalances(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserBalance", reflect.TypeOf((*MockServicer)(nil).GetUserBalance), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserBalances", reflect.TypeOf((*MockServicer)(nil).GetUserBalances), ctx, userID)
 }
 
 // GetUserByLogin mocks base method.
@@ -139,19 +330,49 @@ func (mr *MockServicerMockRecorder) GetUserByLogin(ctx, login interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByLogin", reflect.TypeOf((*MockServicer)(nil).GetUserByLogin), ctx, login)
 }
 
+// GetUserReferrals mocks base method.
+func (m *MockServicer) GetUserReferrals(ctx context.Context, userID string) (models.ReferralModelList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserReferrals", ctx, userID)
+	ret0, _ := ret[0].(models.ReferralModelList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserReferrals indicates an expected call of GetUserReferrals.
+func (mr *MockServicerMockRecorder) GetUserReferrals(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserReferrals", reflect.TypeOf((*MockServicer)(nil).GetUserReferrals), ctx, userID)
+}
+
+// GetUserTier mocks base method.
+func (m *MockServicer) GetUserTier(ctx context.Context, userID string) (*models.UserTierModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTier", ctx, userID)
+	ret0, _ := ret[0].(*models.UserTierModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTier indicates an expected call of GetUserTier.
+func (mr *MockServicerMockRecorder) GetUserTier(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTier", reflect.TypeOf((*MockServicer)(nil).GetUserTier), ctx, userID)
+}
+
 // GetUserWithdrawals mocks base method.
-func (m *MockServicer) GetUserWithdrawals(ctx context.Context, userID string) (models.WithdrawModelList, error) {
+func (m *MockServicer) GetUserWithdrawals(ctx context.Context, userID, programCode string) (models.WithdrawModelList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserWithdrawals", ctx, userID)
+	ret := m.ctrl.Call(m, "GetUserWithdrawals", ctx, userID, programCode)
 	ret0, _ := ret[0].(models.WithdrawModelList)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserWithdrawals indicates an expected call of GetUserWithdrawals.
-func (mr *MockServicerMockRecorder) GetUserWithdrawals(ctx, userID interface{}) *gomock.Call {
+func (mr *MockServicerMockRecorder) GetUserWithdrawals(ctx, userID, programCode interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserWithdrawals", reflect.TypeOf((*MockServicer)(nil).GetUserWithdrawals), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserWithdrawals", reflect.TypeOf((*MockServicer)(nil).GetUserWithdrawals), ctx, userID, programCode)
 }
 
 // IsAccrualSytemBusy mocks base method.
@@ -184,10 +405,39 @@ func (mr *MockServicerMockRecorder) LoginUser(ctx, login, password interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginUser", reflect.TypeOf((*MockServicer)(nil).LoginUser), ctx, login, password)
 }
 
+// PingAccrualSystem mocks base method.
+func (m *MockServicer) PingAccrualSystem(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PingAccrualSystem", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PingAccrualSystem indicates an expected call of PingAccrualSystem.
+func (mr *MockServicerMockRecorder) PingAccrualSystem(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PingAccrualSystem", reflect.TypeOf((*MockServicer)(nil).PingAccrualSystem), ctx)
+}
+
+// RecalculateTiers mocks base method.
+func (m *MockServicer) RecalculateTiers(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecalculateTiers", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecalculateTiers indicates an expected call of RecalculateTiers.
+func (mr *MockServicerMockRecorder) RecalculateTiers(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecalculateTiers", reflect.TypeOf((*MockServicer)(nil).RecalculateTiers), ctx)
+}
+
 // RegisterUser mocks base method.
-func (m *MockServicer) RegisterUser(ctx context.Context, login, password string) (*models.UserModel, string, error) {
+func (m *MockServicer) RegisterUser(ctx context.Context, login, password, referralCode string) (*models.UserModel, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RegisterUser", ctx, login, password)
+	ret := m.ctrl.Call(m, "RegisterUser", ctx, login, password, referralCode)
 	ret0, _ := ret[0].(*models.UserModel)
 	ret1, _ := ret[1].(string)
 	ret2, _ := ret[2].(error)
@@ -195,9 +445,38 @@ func (m *MockServicer) RegisterUser(ctx context.Context, login, password string)
 }
 
 // RegisterUser indicates an expected call of RegisterUser.
-func (mr *MockServicerMockRecorder) RegisterUser(ctx, login, password interface{}) *gomock.Call {
+func (mr *MockServicerMockRecorder) RegisterUser(ctx, login, password, referralCode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterUser", reflect.TypeOf((*MockServicer)(nil).RegisterUser), ctx, login, password, referralCode)
+}
+
+// RetryOrder mocks base method.
+func (m *MockServicer) RetryOrder(ctx context.Context, orderID string) (*models.OrderModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryOrder", ctx, orderID)
+	ret0, _ := ret[0].(*models.OrderModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetryOrder indicates an expected call of RetryOrder.
+func (mr *MockServicerMockRecorder) RetryOrder(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryOrder", reflect.TypeOf((*MockServicer)(nil).RetryOrder), ctx, orderID)
+}
+
+// SyncBatchSize mocks base method.
+func (m *MockServicer) SyncBatchSize(ctx context.Context) int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncBatchSize", ctx)
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// SyncBatchSize indicates an expected call of SyncBatchSize.
+func (mr *MockServicerMockRecorder) SyncBatchSize(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterUser", reflect.TypeOf((*MockServicer)(nil).RegisterUser), ctx, login, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncBatchSize", reflect.TypeOf((*MockServicer)(nil).SyncBatchSize), ctx)
 }
 
 // SyncOrder mocks base method.
@@ -214,6 +493,35 @@ func (mr *MockServicerMockRecorder) SyncOrder(ctx, orderID interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncOrder", reflect.TypeOf((*MockServicer)(nil).SyncOrder), ctx, orderID)
 }
 
+// SyncOrders mocks base method.
+func (m *MockServicer) SyncOrders(ctx context.Context, orderIDs []string) (service.SyncReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncOrders", ctx, orderIDs)
+	ret0, _ := ret[0].(service.SyncReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncOrders indicates an expected call of SyncOrders.
+func (mr *MockServicerMockRecorder) SyncOrders(ctx, orderIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncOrders", reflect.TypeOf((*MockServicer)(nil).SyncOrders), ctx, orderIDs)
+}
+
+// UpdateCampaign mocks base method.
+func (m *MockServicer) UpdateCampaign(ctx context.Context, campaign *models.CampaignModel) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCampaign", ctx, campaign)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCampaign indicates an expected call of UpdateCampaign.
+func (mr *MockServicerMockRecorder) UpdateCampaign(ctx, campaign interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCampaign", reflect.TypeOf((*MockServicer)(nil).UpdateCampaign), ctx, campaign)
+}
+
 // ValidateToken mocks base method.
 func (m *MockServicer) ValidateToken(tokenString string) (string, error) {
 	m.ctrl.T.Helper()
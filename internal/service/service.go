@@ -4,78 +4,214 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/etoneja/go-gophermart/internal/accrualclient"
 	"github.com/etoneja/go-gophermart/internal/config"
 	"github.com/etoneja/go-gophermart/internal/db"
 	"github.com/etoneja/go-gophermart/internal/errs"
+	"github.com/etoneja/go-gophermart/internal/jobs"
+	"github.com/etoneja/go-gophermart/internal/logging"
+	"github.com/etoneja/go-gophermart/internal/metrics"
 	"github.com/etoneja/go-gophermart/internal/models"
 	"github.com/etoneja/go-gophermart/internal/repository"
+	"github.com/etoneja/go-gophermart/internal/tracing"
+	"github.com/etoneja/go-gophermart/internal/utils"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = tracing.Tracer("service")
+
 type Service struct {
 	cfg           *config.Config
 	dbPool        *pgxpool.Pool
 	logger        zerolog.Logger
 	accrualClient accrualclient.AccrualClienter
 	repos         *repository.Repositories
+	jobQueue      *jobs.Queue
+	syncLanes     *laneScheduler
+	workerID      string
 }
 
-func NewService(cfg *config.Config, dbPool *pgxpool.Pool, logger zerolog.Logger) *Service {
+// syncDeferDelay postpones orders whose sync was skipped because the accrual
+// system was rate limited or its circuit breaker was open, so they don't come
+// due again straight away.
+const syncDeferDelay = 5 * time.Second
+
+// SyncOrdersJob syncs a batch of due orders and snoozes until the next order
+// is due, there is at most one of it at a time.
+const SyncOrdersJob = "orders.sync"
+
+// RecalculateTiersJob recalculates all user tiers and snoozes for
+// TierRecalcInterval, there is at most one of it at a time, so replicas
+// don't repeat each other's work.
+const RecalculateTiersJob = "tiers.recalculate"
 
-	accrualClient := accrualclient.NewAccrualClient(cfg.AccrualSystemAddress, 10*time.Second)
+// maxReferralCodeAttempts bounds retries of a registration whose generated
+// referral code collided.
+const maxReferralCodeAttempts = 3
+
+func NewService(cfg *config.Config, dbPool *pgxpool.Pool, logger zerolog.Logger) *Service {
+	accrualClient := newAccrualRegistry(cfg, dbPool, logger)
 	repos := repository.NewRepositories()
 
 	return &Service{
 		cfg:           cfg,
 		dbPool:        dbPool,
+		logger:        logger,
 		accrualClient: accrualClient,
 		repos:         repos,
+		jobQueue:      jobs.NewQueue(dbPool),
+		syncLanes:     newLaneScheduler(cfg.SyncLaneWeights),
+		workerID:      utils.NewWorkerID(),
+	}
+}
+
+// log returns the logger of the request or job ctx belongs to, so service
+// logs carry its request ID or job ID.
+func (s *Service) log(ctx context.Context) *zerolog.Logger {
+	return logging.FromContext(ctx, &s.logger)
+}
+
+// newAccrualRegistry sets up the accrual providers and routes orders to them
+// by number prefix. Each provider gets its own rate limit, breaker, TLS config
+// and signing secret.
+func newAccrualRegistry(cfg *config.Config, dbPool *pgxpool.Pool, logger zerolog.Logger) *accrualclient.Registry {
+	providerOpts := func(limitName string) []accrualclient.Option {
+		limiter := accrualclient.WithRequestsPerMinute(cfg.AccrualRateLimit)
+		if cfg.AccrualSharedLimit {
+			limiter = accrualclient.WithRateLimiter(
+				accrualclient.NewSharedRateLimiter(dbPool, limitName, cfg.AccrualRateLimit, logger))
+		}
+		return []accrualclient.Option{
+			limiter,
+			accrualclient.WithCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+			accrualclient.WithRetries(cfg.AccrualMaxRetries, 200*time.Millisecond),
+			accrualclient.WithBatchConcurrency(cfg.WorkerPoolSize),
+		}
+	}
+
+	defaultOpts := append(providerOpts("accrual"),
+		accrualclient.WithTLSConfig(cfg.AccrualTLS),
+		accrualclient.WithRequestSigning(cfg.AccrualSigningSecret),
+	)
+	registry := accrualclient.NewRegistry(
+		accrualclient.NewAccrualClient(cfg.AccrualSystemAddress, 10*time.Second, defaultOpts...))
+
+	if cfg.AccrualPartnerAddress != "" {
+		partnerOpts := append(providerOpts("accrual-partner"),
+			accrualclient.WithTLSConfig(cfg.AccrualPartnerTLS),
+			accrualclient.WithRequestSigning(cfg.AccrualPartnerSecret),
+		)
+		registry.Register(accrualclient.PartnerProvider, accrualclient.NewPartnerClient(
+			cfg.AccrualPartnerAddress, cfg.AccrualPartnerToken, 10*time.Second, partnerOpts...))
+	}
+
+	for _, route := range cfg.AccrualRoutes {
+		if err := registry.AddRoute(route); err != nil {
+			logger.Error().Err(err).Str("prefix", route.Prefix).Msg("Skipping accrual route")
+		}
 	}
+
+	return registry
 }
 
 func (s *Service) IsAccrualSytemBusy() bool {
-	return s.accrualClient.IsRateLimited()
+	return s.accrualClient.IsRateLimited() ||
+		s.accrualClient.BreakerState() == accrualclient.BreakerStateOpen
+}
+
+// PingAccrualSystem checks every accrual provider is reachable.
+func (s *Service) PingAccrualSystem(ctx context.Context) error {
+	return s.accrualClient.Ping(ctx)
 }
 
-func (s *Service) RegisterUser(ctx context.Context, login, password string) (*models.UserModel, string, error) {
+// RegisterUser creates the user with a fresh referral code, generating another
+// one when the code collides with an existing user's.
+func (s *Service) RegisterUser(ctx context.Context, login, password, referralCode string) (*models.UserModel, string, error) {
 	hashedPassword, err := models.HashPassword(password)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	var token string
-	user := &models.UserModel{
-		UUID:           uuid.NewString(),
-		Login:          login,
-		HashedPassword: hashedPassword,
-		Balance:        0,
-		CreatedAt:      time.Now(),
+	for attempt := 1; ; attempt++ {
+		ownReferralCode, err := models.NewReferralCode()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to generate referral code: %w", err)
+		}
+
+		user := &models.UserModel{
+			UUID:           uuid.NewString(),
+			Login:          login,
+			HashedPassword: hashedPassword,
+			ReferralCode:   ownReferralCode,
+			CreatedAt:      time.Now(),
+		}
+
+		token, err := s.registerUser(ctx, user, referralCode)
+		if errors.Is(err, errs.ErrReferralCodeTaken) && attempt < maxReferralCodeAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create user: %w", err)
+		}
+		return user, token, nil
 	}
+}
 
-	err = db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
+func (s *Service) registerUser(ctx context.Context, user *models.UserModel, referralCode string) (string, error) {
+	var token string
+	err := db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
 		tx := db.GetTxFromContext(txCtx)
+
+		var referrer *models.UserModel
+		if referralCode != "" {
+			var err error
+			getReferrerOpts := repository.GetUserOptions{ReferralCode: referralCode}
+			referrer, err = s.repos.UserRepo.GetUser(txCtx, tx, getReferrerOpts)
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return errs.ErrInvalidReferralCode
+				}
+				return fmt.Errorf("can't get referrer: %w", err)
+			}
+		}
+
 		err := s.repos.UserRepo.CreateUser(txCtx, tx, user)
 		if err != nil {
 			return err
 		}
+		err = s.repos.AccountRepo.EnsureAccount(txCtx, tx, models.NewAccount(user.UUID, models.DefaultProgramCode))
+		if err != nil {
+			return err
+		}
+
+		if referrer != nil {
+			referral := &models.ReferralModel{
+				RefereeID:  user.UUID,
+				ReferrerID: referrer.UUID,
+				Status:     models.ReferralStatusPending,
+				CreatedAt:  user.CreatedAt,
+			}
+			err = s.repos.ReferralRepo.CreateReferral(txCtx, tx, referral)
+			if err != nil {
+				return fmt.Errorf("can't create referral: %w", err)
+			}
+		}
 		token, err = s.generateJWTToken(user.Login)
 		if err != nil {
 			return err
 		}
 		return nil
 	})
-
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to create user: %w", err)
-	}
-
-	return user, token, nil
+	return token, err
 }
 
 func (s *Service) LoginUser(ctx context.Context, login, password string) (*models.UserModel, string, error) {
@@ -160,13 +296,17 @@ func (s *Service) GetUserByLogin(ctx context.Context, login string) (*models.Use
 	return user, nil
 }
 
-func (s *Service) GetUserBalance(ctx context.Context, userID string) (*models.BalanceModel, error) {
+func (s *Service) GetUserBalance(ctx context.Context, userID, programCode string) (*models.BalanceModel, error) {
 	var balance *models.BalanceModel
 	err := db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
 		tx := db.GetTxFromContext(txCtx)
 
+		if err := s.checkProgramExists(txCtx, tx, programCode); err != nil {
+			return err
+		}
+
 		var err error
-		balance, err = s.repos.UserRepo.GetUserBalance(txCtx, tx, userID)
+		balance, err = s.repos.AccountRepo.GetAccountBalance(txCtx, tx, userID, programCode)
 		if err != nil {
 			return err
 		}
@@ -181,14 +321,47 @@ func (s *Service) GetUserBalance(ctx context.Context, userID string) (*models.Ba
 
 }
 
+func (s *Service) GetUserBalances(ctx context.Context, userID string) (models.BalanceModelList, error) {
+	var balances models.BalanceModelList
+	err := db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
+		tx := db.GetTxFromContext(txCtx)
+		var err error
+		balances, err = s.repos.AccountRepo.GetAccountBalances(txCtx, tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+func (s *Service) checkProgramExists(ctx context.Context, tx pgx.Tx, programCode string) error {
+	_, err := s.repos.ProgramRepo.GetProgram(ctx, tx, programCode)
+	if err != nil {
+		if errors.Is(err, errs.ErrNoRows) {
+			return errs.ErrProgramNotFound
+		}
+		return fmt.Errorf("can't get program: %w", err)
+	}
+	return nil
+}
+
 func (s *Service) CreateOrGetOrder(ctx context.Context, order *models.OrderModel) (*models.OrderModel, error) {
 	err := db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
 		tx := db.GetTxFromContext(txCtx)
+
+		if err := s.checkProgramExists(txCtx, tx, order.ProgramCode); err != nil {
+			return err
+		}
+
 		return s.repos.OrderRepo.CreateOrder(ctx, tx, order)
 	})
 	if err != nil {
 		return nil, err
 	}
+
+	s.wakeOrdersSync(ctx)
 	return order, nil
 }
 
@@ -207,19 +380,130 @@ func (s *Service) GetOrdersForUser(ctx context.Context, user *models.UserModel)
 	return orders, nil
 }
 
-func (s *Service) GetOrdersToSync(ctx context.Context, limit int) (models.OrderModelList, error) {
+// ClaimOrdersToSync leases up to limit due orders to this worker for
+// SyncOrders. Leases expire after SyncLease, so orders of a crashed worker
+// are picked up by others.
+//
+// The limit is split between the sync lanes by SyncLaneWeights, slots a lane
+// has no due orders for go to the other lanes by priority.
+func (s *Service) ClaimOrdersToSync(ctx context.Context, limit int) (models.OrderModelList, error) {
+	shares := s.syncLanes.allocate(limit)
+	opts := s.claimOptions()
+
 	var orders models.OrderModelList
+	err := db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
+		tx := db.GetTxFromContext(txCtx)
+
+		claim := func(lane models.SyncLane, n int) error {
+			opts.Lane = lane
+			opts.Limit = n
+			claimed, err := s.repos.OrderRepo.ClaimOrdersToSync(txCtx, tx, opts)
+			orders = append(orders, claimed...)
+			return err
+		}
+
+		for _, lane := range models.SyncLanes {
+			if shares[lane] == 0 {
+				continue
+			}
+			if err := claim(lane, shares[lane]); err != nil {
+				return err
+			}
+		}
+		for _, lane := range models.SyncLanes {
+			if len(orders) >= limit {
+				break
+			}
+			if err := claim(lane, limit-len(orders)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (s *Service) claimOptions() repository.ClaimOrdersOptions {
+	now := time.Now()
+	return repository.ClaimOrdersOptions{
+		WorkerID:    s.workerID,
+		StaleBefore: now.Add(-s.cfg.SyncStaleAfter),
+		Now:         now,
+		Until:       now.Add(s.cfg.SyncLease),
+	}
+}
+
+// GetOrdersBacklog returns the number of non-terminal orders by status.
+func (s *Service) GetOrdersBacklog(ctx context.Context) (map[models.OrderStatus]int64, error) {
+	var backlog map[models.OrderStatus]int64
 	err := db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
 		tx := db.GetTxFromContext(txCtx)
 		var err error
-		orders, err = s.repos.OrderRepo.GetOrdersToSync(ctx, tx, limit)
+		backlog, err = s.repos.OrderRepo.CountOrdersBacklog(txCtx, tx)
 		return err
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return orders, nil
+	return backlog, nil
+}
+
+// EnsureOrdersSync creates the orders sync job if it doesn't exist or has
+// been dead-lettered. An existing job keeps its schedule and backoff.
+func (s *Service) EnsureOrdersSync(ctx context.Context) error {
+	_, err := s.jobQueue.Enqueue(ctx, nil, jobs.EnqueueParams{
+		Kind:      SyncOrdersJob,
+		UniqueKey: SyncOrdersJob,
+		IfMissing: true,
+	})
+	return err
+}
+
+// EnsureTiersRecalc creates the tier recalculation job if it doesn't exist or
+// has been dead-lettered. An existing job keeps its schedule.
+func (s *Service) EnsureTiersRecalc(ctx context.Context) error {
+	_, err := s.jobQueue.Enqueue(ctx, nil, jobs.EnqueueParams{
+		Kind:      RecalculateTiersJob,
+		UniqueKey: RecalculateTiersJob,
+		IfMissing: true,
+	})
+	return err
+}
+
+// wakeOrdersSync makes the orders sync job run now. It is called after the
+// order is committed, so uploads don't queue up on the job row while their
+// transactions are open. A failure only delays the sync until the next
+// scheduled run or processor sweep.
+func (s *Service) wakeOrdersSync(ctx context.Context) {
+	_, err := s.jobQueue.Enqueue(ctx, nil, jobs.EnqueueParams{
+		Kind:      SyncOrdersJob,
+		UniqueKey: SyncOrdersJob,
+	})
+	if err != nil {
+		s.log(ctx).Warn().Err(err).Msg("Failed to schedule orders sync")
+	}
+}
+
+// GetNextSyncAt returns when the earliest scheduled order sync is due, nil
+// when none is scheduled.
+func (s *Service) GetNextSyncAt(ctx context.Context) (*time.Time, error) {
+	var nextSyncAt *time.Time
+	err := db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
+		tx := db.GetTxFromContext(txCtx)
+		var err error
+		nextSyncAt, err = s.repos.OrderRepo.GetNextSyncAt(txCtx, tx, time.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nextSyncAt, nil
 }
 
 func (s *Service) GetOrder(ctx context.Context, orderID string) (*models.OrderModel, error) {
@@ -244,12 +528,17 @@ func (s *Service) GetOrder(ctx context.Context, orderID string) (*models.OrderMo
 
 }
 
-func (s *Service) GetUserWithdrawals(ctx context.Context, userID string) (models.WithdrawModelList, error) {
+func (s *Service) GetUserWithdrawals(ctx context.Context, userID, programCode string) (models.WithdrawModelList, error) {
 	var withdrawals models.WithdrawModelList
 	err := db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
 		tx := db.GetTxFromContext(txCtx)
+
+		if err := s.checkProgramExists(txCtx, tx, programCode); err != nil {
+			return err
+		}
+
 		var err error
-		withdrawals, err = s.repos.UserRepo.GetUserWithdrawals(ctx, tx, userID)
+		withdrawals, err = s.repos.UserRepo.GetUserWithdrawals(ctx, tx, userID, programCode)
 		return err
 	})
 	if err != nil {
@@ -259,134 +548,981 @@ func (s *Service) GetUserWithdrawals(ctx context.Context, userID string) (models
 	return withdrawals, nil
 }
 
-func (s *Service) CreateWithdraw(ctx context.Context, withdraw *models.WithdrawModel) error {
-	err := db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
+func (s *Service) CreateWithdraw(ctx context.Context, withdraw *models.WithdrawModel) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.CreateWithdraw", trace.WithAttributes(
+		attribute.String("order.id", withdraw.OrderID),
+		attribute.String("program.code", withdraw.ProgramCode),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	err = db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
 		if withdraw.Sum <= 0 {
 			return errors.New("withdraw Sum should be positive")
 		}
 
 		tx := db.GetTxFromContext(txCtx)
 
-		opts := repository.GetUserOptions{
-			UUID:          withdraw.UserID,
+		if err := s.checkProgramExists(txCtx, tx, withdraw.ProgramCode); err != nil {
+			return err
+		}
+
+		opts := repository.GetAccountOptions{
+			UserID:        withdraw.UserID,
+			ProgramCode:   withdraw.ProgramCode,
 			LockForUpdate: true,
 		}
-		user, err := s.repos.UserRepo.GetUser(txCtx, tx, opts)
+		account, err := s.repos.AccountRepo.GetAccount(txCtx, tx, opts)
 		if err != nil {
-			return fmt.Errorf("can't get user: %w", err)
+			if errors.Is(err, errs.ErrNoRows) {
+				return errs.ErrInsufficientFunds
+			}
+			return fmt.Errorf("can't get account: %w", err)
 		}
 
-		if user.Balance < withdraw.Sum {
+		if account.Balance < withdraw.Sum {
 			return errs.ErrInsufficientFunds
 		}
 
 		transaction := &models.TransactionModel{
-			UUID:      uuid.NewString(),
-			UserID:    withdraw.UserID,
-			OrderID:   withdraw.OrderID,
-			Type:      models.TransactionTypeWithdraw,
-			Amount:    withdraw.Sum,
-			CreatedAt: withdraw.CreatedAt,
-		}
-
-		err = s.repos.TransactionRepo.CreateTransaction(ctx, tx, transaction)
-		if err != nil {
-			return fmt.Errorf("can't create transaction: %w", err)
-		}
-
-		err = s.repos.UserRepo.UpdateUserBalance(txCtx, tx, transaction)
-		if err != nil {
-			return fmt.Errorf("can't update user balance: %w", err)
+			UUID:        uuid.NewString(),
+			UserID:      withdraw.UserID,
+			ProgramCode: withdraw.ProgramCode,
+			OrderID:     withdraw.OrderID,
+			Type:        models.TransactionTypeWithdraw,
+			Amount:      withdraw.Sum,
+			CreatedAt:   withdraw.CreatedAt,
 		}
 
-		return nil
+		return s.postTransaction(txCtx, tx, transaction)
 	})
 	return err
 }
 
-func (s *Service) SyncOrder(ctx context.Context, orderID string) error {
+// CreateTransfer moves points between two users of the same program. Replays
+// with the same idempotency key return the originally created transfer.
+func (s *Service) CreateTransfer(ctx context.Context, transfer *models.TransferModel) (*models.TransferModel, error) {
+	result := transfer
 	err := db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
+		if transfer.Amount <= 0 {
+			return errors.New("transfer amount should be positive")
+		}
 
 		tx := db.GetTxFromContext(txCtx)
 
-		getOrderOpts := repository.GetOrderOptions{
-			ID:            orderID,
-			LockForUpdate: true,
-			SkipLocked:    true,
+		if err := s.checkProgramExists(txCtx, tx, transfer.ProgramCode); err != nil {
+			return err
 		}
-		order, err := s.repos.OrderRepo.GetOrder(txCtx, tx, getOrderOpts)
+
+		getRecipientOpts := repository.GetUserOptions{Login: transfer.RecipientLogin}
+		recipient, err := s.repos.UserRepo.GetUser(txCtx, tx, getRecipientOpts)
 		if err != nil {
-			if errors.Is(err, errs.ErrNoRows) {
-				s.logger.Warn().
-					Str("orderID", orderID).
-					Err(err).
-					Msg("can't get order for update, skipping...")
+			if errors.Is(err, pgx.ErrNoRows) {
+				return errs.ErrRecipientNotFound
+			}
+			return fmt.Errorf("can't get recipient: %w", err)
+		}
+		transfer.RecipientID = recipient.UUID
+
+		if transfer.RecipientID == transfer.SenderID {
+			return errs.ErrSelfTransfer
+		}
+
+		err = s.repos.AccountRepo.EnsureAccount(txCtx, tx, models.NewAccount(transfer.RecipientID, transfer.ProgramCode))
+		if err != nil {
+			return fmt.Errorf("can't create account: %w", err)
+		}
+
+		// Accounts are always locked in UUID order so concurrent opposite
+		// transfers can't deadlock.
+		userIDs := []string{transfer.SenderID, transfer.RecipientID}
+		slices.Sort(userIDs)
+
+		accounts := make(map[string]*models.AccountModel, len(userIDs))
+		for _, userID := range userIDs {
+			getAccountOpts := repository.GetAccountOptions{
+				UserID:        userID,
+				ProgramCode:   transfer.ProgramCode,
+				LockForUpdate: true,
+			}
+			account, err := s.repos.AccountRepo.GetAccount(txCtx, tx, getAccountOpts)
+			if err != nil && !errors.Is(err, errs.ErrNoRows) {
+				return fmt.Errorf("can't get account: %w", err)
+			}
+			accounts[userID] = account
+		}
+
+		if transfer.IdempotencyKey != nil {
+			existing, err := s.repos.TransferRepo.GetTransferByIdempotencyKey(txCtx, tx, transfer.SenderID, *transfer.IdempotencyKey)
+			if err != nil && !errors.Is(err, errs.ErrNoRows) {
+				return fmt.Errorf("can't get transfer: %w", err)
+			}
+			if existing != nil {
+				if !existing.Matches(transfer) {
+					return errs.ErrIdempotencyKeyReused
+				}
+				result = existing
 				return nil
 			}
-			return fmt.Errorf("failed to get order from db: %w", err)
 		}
-		if order.IsTerminated() {
-			s.logger.Info().
-				Str("orderID", orderID).
-				Err(err).
-				Msg("order already processed, skipping...")
-			return nil
+
+		sender := accounts[transfer.SenderID]
+		if sender == nil || sender.Balance < transfer.Amount {
+			return errs.ErrInsufficientFunds
 		}
 
-		accrualOrder, err := s.accrualClient.GetOrder(txCtx, orderID)
+		transferred, err := s.repos.TransferRepo.SumTransfersSince(txCtx, tx, transfer.SenderID, transfer.ProgramCode, transfer.CreatedAt.Add(-24*time.Hour))
 		if err != nil {
-			return fmt.Errorf("failed to get order from accrual system: %w", err)
+			return fmt.Errorf("can't sum transfers: %w", err)
+		}
+		if transferred+transfer.Amount > s.cfg.TransferDailyLimit {
+			return errs.ErrTransferLimitExceeded
 		}
 
-		newOrderStatus, err := models.ConvertAccrualOrderStatusToOrderStatus(accrualOrder.Status)
+		transfer.UUID = uuid.NewString()
+		err = s.repos.TransferRepo.CreateTransfer(txCtx, tx, transfer)
 		if err != nil {
-			return err
+			return fmt.Errorf("can't create transfer: %w", err)
+		}
+
+		transactions := []*models.TransactionModel{
+			{
+				UUID:        uuid.NewString(),
+				UserID:      transfer.SenderID,
+				ProgramCode: transfer.ProgramCode,
+				TransferID:  &transfer.UUID,
+				Type:        models.TransactionTypeTransferOut,
+				Amount:      transfer.Amount,
+				CreatedAt:   transfer.CreatedAt,
+			},
+			{
+				UUID:        uuid.NewString(),
+				UserID:      transfer.RecipientID,
+				ProgramCode: transfer.ProgramCode,
+				TransferID:  &transfer.UUID,
+				Type:        models.TransactionTypeTransferIn,
+				Amount:      transfer.Amount,
+				CreatedAt:   transfer.CreatedAt,
+			},
+		}
+		for _, transaction := range transactions {
+			if err := s.postTransaction(txCtx, tx, transaction); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ConvertBalance moves points between two programs of the same user at the
+// programs' conversion rates, the converted amount is rounded down.
+func (s *Service) ConvertBalance(ctx context.Context, conversion *models.ConversionModel) error {
+	return db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
+		if conversion.Amount <= 0 {
+			return errors.New("conversion amount should be positive")
+		}
+		if conversion.FromProgramCode == conversion.ToProgramCode {
+			return errs.ErrSameProgram
+		}
+
+		tx := db.GetTxFromContext(txCtx)
+
+		programs := make(map[string]*models.ProgramModel, 2)
+		for _, code := range []string{conversion.FromProgramCode, conversion.ToProgramCode} {
+			program, err := s.repos.ProgramRepo.GetProgram(txCtx, tx, code)
+			if err != nil {
+				if errors.Is(err, errs.ErrNoRows) {
+					return errs.ErrProgramNotFound
+				}
+				return fmt.Errorf("can't get program: %w", err)
+			}
+			programs[code] = program
 		}
 
-		order.Status = newOrderStatus
-		order.UpdatedAt = time.Now()
-		order.Accrual = accrualOrder.Accrual
+		converted, ok := programs[conversion.FromProgramCode].ConvertTo(programs[conversion.ToProgramCode], conversion.Amount)
+		if !ok {
+			return errs.ErrConversionOutOfRange
+		}
+		if converted == 0 {
+			return errs.ErrConversionTooSmall
+		}
+		conversion.Converted = converted
 
-		err = s.repos.OrderRepo.UpdateOrder(txCtx, tx, order)
+		err := s.repos.AccountRepo.EnsureAccount(txCtx, tx, models.NewAccount(conversion.UserID, conversion.ToProgramCode))
 		if err != nil {
-			return fmt.Errorf("can't update order: %w", err)
+			return fmt.Errorf("can't create account: %w", err)
 		}
 
-		if accrualOrder.Status == models.AccrualOrderStatusProcessed {
-			getUserOps := repository.GetUserOptions{
-				UUID:          order.UserID,
+		// Accounts are always locked in program order so concurrent opposite
+		// conversions can't deadlock.
+		programCodes := []string{conversion.FromProgramCode, conversion.ToProgramCode}
+		slices.Sort(programCodes)
+
+		accounts := make(map[string]*models.AccountModel, len(programCodes))
+		for _, programCode := range programCodes {
+			getAccountOpts := repository.GetAccountOptions{
+				UserID:        conversion.UserID,
+				ProgramCode:   programCode,
 				LockForUpdate: true,
 			}
-			_, err = s.repos.UserRepo.GetUser(txCtx, tx, getUserOps)
-			if err != nil {
-				return fmt.Errorf("can't get user: %w", err)
+			account, err := s.repos.AccountRepo.GetAccount(txCtx, tx, getAccountOpts)
+			if err != nil && !errors.Is(err, errs.ErrNoRows) {
+				return fmt.Errorf("can't get account: %w", err)
 			}
+			accounts[programCode] = account
+		}
+
+		source := accounts[conversion.FromProgramCode]
+		if source == nil || source.Balance < conversion.Amount {
+			return errs.ErrInsufficientFunds
+		}
 
-			transaction := &models.TransactionModel{
-				UUID:      uuid.NewString(),
-				UserID:    order.UserID,
-				OrderID:   order.ID,
-				Type:      models.TransactionTypeAccrual,
-				Amount:    *order.Accrual,
-				CreatedAt: time.Now(),
+		transactions := []*models.TransactionModel{
+			{
+				UUID:        uuid.NewString(),
+				UserID:      conversion.UserID,
+				ProgramCode: conversion.FromProgramCode,
+				Type:        models.TransactionTypeConversionOut,
+				Amount:      conversion.Amount,
+				CreatedAt:   conversion.CreatedAt,
+			},
+			{
+				UUID:        uuid.NewString(),
+				UserID:      conversion.UserID,
+				ProgramCode: conversion.ToProgramCode,
+				Type:        models.TransactionTypeConversionIn,
+				Amount:      conversion.Converted,
+				CreatedAt:   conversion.CreatedAt,
+			},
+		}
+		for _, transaction := range transactions {
+			if err := s.postTransaction(txCtx, tx, transaction); err != nil {
+				return err
 			}
+		}
+
+		return nil
+	})
+}
+
+// SyncOrder claims a single order and syncs it like SyncOrders. It does
+// nothing when the order isn't due or is leased by another worker.
+func (s *Service) SyncOrder(ctx context.Context, orderID string) error {
+	opts := s.claimOptions()
+	opts.OrderID = orderID
+	opts.Limit = 1
+
+	var orders models.OrderModelList
+	err := db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
+		tx := db.GetTxFromContext(txCtx)
+		var err error
+		orders, err = s.repos.OrderRepo.ClaimOrdersToSync(txCtx, tx, opts)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(orders) == 0 {
+		return nil
+	}
+	_, err = s.SyncOrders(ctx, []string{orderID})
+	return err
+}
+
+// SyncReport summarizes a SyncOrders call.
+type SyncReport struct {
+	// RateLimited counts orders the accrual system answered with 429.
+	RateLimited int
+}
+
+// SyncBatchSize is how many claimed orders to pass to one SyncOrders call:
+// the accrual providers' batch size, capped by the claim batch size.
+func (s *Service) SyncBatchSize(ctx context.Context) int {
+	limit := max(s.cfg.SyncBatchSize, 1)
+	if size := s.accrualClient.BatchSize(ctx); size > 0 {
+		return min(size, limit)
+	}
+	return limit
+}
+
+// SyncOrders syncs orders leased by ClaimOrdersToSync. The accrual system is
+// queried with no transaction open, then the results are applied in one short
+// transaction. Each order is applied under its own savepoint, so a failing
+// order is recorded as a sync failure without rolling back the rest of the
+// batch. The report is returned even when applying the results failed.
+func (s *Service) SyncOrders(ctx context.Context, orderIDs []string) (report SyncReport, err error) {
+	ctx, span := tracer.Start(ctx, "Service.SyncOrders", trace.WithAttributes(
+		attribute.StringSlice("order.ids", orderIDs),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	results := s.accrualClient.GetOrders(ctx, orderIDs)
+	for _, result := range results {
+		if errors.Is(result.Err, errs.ErrRateLimit) {
+			report.RateLimited++
+		}
+	}
+
+	// Outcomes are counted once the transaction has committed.
+	var outcomes map[string]int
+	err = db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
+		tx := db.GetTxFromContext(txCtx)
+		outcomes = make(map[string]int)
 
-			err = s.repos.TransactionRepo.CreateTransaction(ctx, tx, transaction)
+		for _, result := range results {
+			order, err := s.takeClaimedOrder(txCtx, tx, result.ID)
 			if err != nil {
-				return fmt.Errorf("can't create transaction: %w", err)
+				return err
+			}
+			if order == nil {
+				outcomes[metrics.SyncOutcomeSkipped]++
+				continue
 			}
 
-			err = s.repos.UserRepo.UpdateUserBalance(txCtx, tx, transaction)
+			savepoint, err := tx.Begin(txCtx)
 			if err != nil {
-				return fmt.Errorf("can't update user balance: %w", err)
+				return fmt.Errorf("can't create savepoint: %w", err)
+			}
+
+			syncErr := s.applyOrderResult(txCtx, savepoint, order, result)
+			if syncErr == nil {
+				if err := savepoint.Commit(txCtx); err != nil {
+					return fmt.Errorf("can't release savepoint: %w", err)
+				}
+				outcomes[metrics.SyncOutcomeApplied]++
+				continue
 			}
 
+			if err := savepoint.Rollback(txCtx); err != nil {
+				return fmt.Errorf("can't rollback savepoint: %w", err)
+			}
+			if !isSyncFailure(ctx, syncErr) {
+				if ctx.Err() == nil {
+					if err := s.deferOrderSync(txCtx, tx, order); err != nil {
+						return fmt.Errorf("can't defer order sync: %w", err)
+					}
+				}
+				outcomes[metrics.SyncOutcomeDeferred]++
+				continue
+			}
+
+			s.log(ctx).Error().
+				Str("orderID", result.ID).
+				Err(syncErr).
+				Msg("order sync failed")
+			outcome, err := s.recordSyncFailure(txCtx, tx, result.ID, syncErr)
+			if err != nil {
+				return fmt.Errorf("can't record sync failure: %w", err)
+			}
+			outcomes[outcome]++
 		}
-		s.logger.Info().
-			Str("orderID", orderID).
-			Msg("order processed sucessfully")
+
 		return nil
 	})
+	if err != nil {
+		return report, err
+	}
 
-	return err
+	for outcome, n := range outcomes {
+		metrics.SyncOutcomes.WithLabelValues(outcome).Add(float64(n))
+	}
+	return report, nil
+}
+
+// isSyncFailure reports whether a sync error counts against the order. Rate
+// limiting, an unavailable shared limiter, an open circuit breaker and
+// shutdown say nothing about the order.
+func isSyncFailure(ctx context.Context, err error) bool {
+	return !errors.Is(err, errs.ErrRateLimit) &&
+		!errors.Is(err, accrualclient.ErrLimiterUnavailable) &&
+		!errors.Is(err, accrualclient.ErrCircuitOpen) &&
+		ctx.Err() == nil
+}
+
+// recordSyncFailure bumps the attempt counter of the order and schedules the
+// next sync with exponential backoff, dead-lettering it after too many failures.
+// It returns the sync outcome to count.
+func (s *Service) recordSyncFailure(ctx context.Context, tx pgx.Tx, orderID string, syncErr error) (string, error) {
+	getOrderOpts := repository.GetOrderOptions{
+		ID:            orderID,
+		LockForUpdate: true,
+	}
+	order, err := s.repos.OrderRepo.GetOrder(ctx, tx, getOrderOpts)
+	if err != nil {
+		return "", fmt.Errorf("failed to get order from db: %w", err)
+	}
+	if order.IsTerminated() || order.IsDeadLettered() {
+		return metrics.SyncOutcomeSkipped, nil
+	}
+
+	backoff := utils.Backoff(order.SyncAttempts+1, s.cfg.SyncBackoffBase, s.cfg.SyncBackoffMax)
+	order.MarkSyncFailed(syncErr, time.Now(), backoff, s.cfg.SyncMaxAttempts)
+
+	outcome := metrics.SyncOutcomeFailed
+	if order.IsDeadLettered() {
+		outcome = metrics.SyncOutcomeDeadLettered
+		s.log(ctx).Warn().
+			Str("orderID", orderID).
+			Int("attempts", order.SyncAttempts).
+			Msg("order dead-lettered after too many failed syncs")
+	}
+	return outcome, s.repos.OrderRepo.UpdateOrder(ctx, tx, order)
+}
+
+// deferOrderSync pushes the next sync of the order by syncDeferDelay without
+// counting an attempt.
+func (s *Service) deferOrderSync(ctx context.Context, tx pgx.Tx, order *models.OrderModel) error {
+	now := time.Now()
+	order.DeferSync(now.Add(syncDeferDelay), now)
+	return s.repos.OrderRepo.UpdateOrder(ctx, tx, order)
+}
+
+// takeClaimedOrder locks an order leased by this worker and releases the
+// lease. It returns nil when the lease was taken over by another worker or
+// the order doesn't need syncing anymore.
+func (s *Service) takeClaimedOrder(ctx context.Context, tx pgx.Tx, orderID string) (*models.OrderModel, error) {
+	getOrderOpts := repository.GetOrderOptions{
+		ID:            orderID,
+		LockForUpdate: true,
+	}
+	order, err := s.repos.OrderRepo.GetOrder(ctx, tx, getOrderOpts)
+	if err != nil {
+		if errors.Is(err, errs.ErrNoRows) {
+			s.log(ctx).Warn().
+				Str("orderID", orderID).
+				Err(err).
+				Msg("can't get order for update, skipping...")
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get order from db: %w", err)
+	}
+	if !order.IsClaimedBy(s.workerID) {
+		s.log(ctx).Warn().
+			Str("orderID", orderID).
+			Msg("order lease taken over by another worker, skipping...")
+		return nil, nil
+	}
+
+	order.ReleaseClaim()
+	if err := s.repos.OrderRepo.UpdateOrder(ctx, tx, order); err != nil {
+		return nil, fmt.Errorf("can't release order lease: %w", err)
+	}
+
+	if order.IsTerminated() {
+		s.log(ctx).Info().
+			Str("orderID", orderID).
+			Msg("order already processed, skipping...")
+		return nil, nil
+	}
+	if order.IsDeadLettered() {
+		s.log(ctx).Info().
+			Str("orderID", orderID).
+			Msg("order dead-lettered, skipping...")
+		return nil, nil
+	}
+	return order, nil
+}
+
+// applyOrderResult applies the accrual system's answer for a locked order.
+func (s *Service) applyOrderResult(ctx context.Context, tx pgx.Tx, order *models.OrderModel, result accrualclient.OrderResult) error {
+	if errors.Is(result.Err, accrualclient.ErrOrderNotRegistered) {
+		return s.handleUnregisteredOrder(ctx, tx, order)
+	}
+	if result.Err != nil {
+		return fmt.Errorf("failed to get order from accrual system: %w", result.Err)
+	}
+
+	err := s.applyAccrualOrder(ctx, tx, order, result.Order)
+	if err != nil {
+		return err
+	}
+
+	s.log(ctx).Info().
+		Str("orderID", order.ID).
+		Msg("order processed sucessfully")
+	return nil
+}
+
+// ApplyAccrualUpdate applies an order status pushed by the accrual system the
+// same way SyncOrders applies a polled one. Updates for terminated or
+// dead-lettered orders are ignored so redelivered callbacks are harmless.
+func (s *Service) ApplyAccrualUpdate(ctx context.Context, accrualOrder *models.AccrualOrderModel) error {
+	return db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
+		tx := db.GetTxFromContext(txCtx)
+
+		getOrderOpts := repository.GetOrderOptions{
+			ID:            accrualOrder.ID,
+			LockForUpdate: true,
+		}
+		order, err := s.repos.OrderRepo.GetOrder(txCtx, tx, getOrderOpts)
+		if err != nil {
+			return fmt.Errorf("failed to get order from db: %w", err)
+		}
+		if order.IsTerminated() || order.IsDeadLettered() {
+			s.log(ctx).Info().
+				Str("orderID", order.ID).
+				Msg("order already processed, ignoring accrual callback")
+			return nil
+		}
+
+		err = s.applyAccrualOrder(txCtx, tx, order, accrualOrder)
+		if err != nil {
+			return err
+		}
+
+		s.log(ctx).Info().
+			Str("orderID", order.ID).
+			Str("status", string(order.Status)).
+			Msg("order updated from accrual callback")
+		return nil
+	})
+}
+
+// applyAccrualOrder moves a locked order to the status reported by the accrual
+// system, crediting the accrual together with referral and campaign bonuses.
+// Orders not terminated yet are rechecked after SyncRecheckDelay.
+func (s *Service) applyAccrualOrder(ctx context.Context, tx pgx.Tx, order *models.OrderModel, accrualOrder *models.AccrualOrderModel) error {
+	newOrderStatus, err := models.ConvertAccrualOrderStatusToOrderStatus(accrualOrder.Status)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	order.Status = newOrderStatus
+	order.UpdatedAt = now
+	order.Accrual = nil
+	if order.IsTerminated() {
+		order.MarkSyncSucceeded()
+	} else {
+		// The accrual system is still working on the order, look again after
+		// a while rather than as soon as the claim is released.
+		order.ScheduleSync(now.Add(s.cfg.SyncRecheckDelay), now)
+	}
+
+	var tier models.Tier
+	if accrualOrder.Status == models.AccrualOrderStatusProcessed && accrualOrder.Accrual != nil {
+		program, err := s.repos.ProgramRepo.GetProgram(ctx, tx, order.ProgramCode)
+		if err != nil {
+			return fmt.Errorf("can't get program: %w", err)
+		}
+
+		tier, err = s.getUserTier(ctx, tx, order.UserID)
+		if err != nil {
+			return err
+		}
+
+		amount := tier.ApplyMultiplier(program.ApplyAccrualRate(*accrualOrder.Accrual))
+		if amount > 0 {
+			order.Accrual = &amount
+		}
+	}
+
+	err = s.repos.OrderRepo.UpdateOrder(ctx, tx, order)
+	if err != nil {
+		return fmt.Errorf("can't update order: %w", err)
+	}
+
+	if order.Status == models.OrderStatusProcessed {
+		err = s.rewardReferral(ctx, tx, order)
+		if err != nil {
+			return err
+		}
+	}
+
+	if order.Accrual != nil {
+		err = s.repos.AccountRepo.EnsureAccount(ctx, tx, models.NewAccount(order.UserID, order.ProgramCode))
+		if err != nil {
+			return fmt.Errorf("can't create account: %w", err)
+		}
+
+		getAccountOpts := repository.GetAccountOptions{
+			UserID:        order.UserID,
+			ProgramCode:   order.ProgramCode,
+			LockForUpdate: true,
+		}
+		_, err = s.repos.AccountRepo.GetAccount(ctx, tx, getAccountOpts)
+		if err != nil {
+			return fmt.Errorf("can't get account: %w", err)
+		}
+
+		transaction := &models.TransactionModel{
+			UUID:        uuid.NewString(),
+			UserID:      order.UserID,
+			ProgramCode: order.ProgramCode,
+			OrderID:     order.ID,
+			Type:        models.TransactionTypeAccrual,
+			Amount:      *order.Accrual,
+			CreatedAt:   time.Now(),
+		}
+
+		err = s.postTransaction(ctx, tx, transaction)
+		if err != nil {
+			return err
+		}
+
+		err = s.applyCampaigns(ctx, tx, order, tier)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleUnregisteredOrder keeps an order unknown to the accrual system NEW and
+// schedules a recheck, invalidating it once it is older than UnregisteredTTL.
+func (s *Service) handleUnregisteredOrder(ctx context.Context, tx pgx.Tx, order *models.OrderModel) error {
+	now := time.Now()
+
+	if now.Sub(order.CreatedAt) >= s.cfg.UnregisteredTTL {
+		order.Status = models.OrderStatusInvalid
+		order.UpdatedAt = now
+		order.MarkSyncSucceeded()
+		s.log(ctx).Info().
+			Str("orderID", order.ID).
+			Msg("order never registered in accrual system, invalidating")
+	} else {
+		order.Status = models.OrderStatusNew
+		order.ScheduleSync(now.Add(s.cfg.UnregisteredRecheck), now)
+	}
+
+	err := s.repos.OrderRepo.UpdateOrder(ctx, tx, order)
+	if err != nil {
+		return fmt.Errorf("can't update order: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) postTransaction(ctx context.Context, tx pgx.Tx, transaction *models.TransactionModel) error {
+	err := s.repos.TransactionRepo.CreateTransaction(ctx, tx, transaction)
+	if err != nil {
+		return fmt.Errorf("can't create transaction: %w", err)
+	}
+
+	err = s.repos.AccountRepo.UpdateAccountBalance(ctx, tx, transaction)
+	if err != nil {
+		return fmt.Errorf("can't update account balance: %w", err)
+	}
+
+	return nil
+}
+
+// applyCampaigns posts a bonus transaction for every running campaign the
+// credited order is eligible for.
+func (s *Service) applyCampaigns(ctx context.Context, tx pgx.Tx, order *models.OrderModel, tier models.Tier) error {
+	now := time.Now()
+
+	campaigns, err := s.repos.CampaignRepo.GetRunningCampaigns(ctx, tx, order.ProgramCode, now)
+	if err != nil {
+		return fmt.Errorf("can't get running campaigns: %w", err)
+	}
+	if len(campaigns) == 0 {
+		return nil
+	}
+
+	hasOtherOrders, err := s.repos.OrderRepo.HasOtherProcessedOrders(ctx, tx, order.UserID, order.ID)
+	if err != nil {
+		return fmt.Errorf("can't check previous orders: %w", err)
+	}
+
+	evalCtx := models.CampaignEvalContext{
+		Accrual:     *order.Accrual,
+		IsFirst:     !hasOtherOrders,
+		Tier:        tier.Level,
+		ProcessedAt: now,
+	}
+
+	for _, campaign := range campaigns {
+		if !campaign.IsEligible(evalCtx) {
+			continue
+		}
+
+		bonus := campaign.BonusFor(evalCtx)
+		if bonus <= 0 {
+			continue
+		}
+
+		transaction := &models.TransactionModel{
+			UUID:        uuid.NewString(),
+			UserID:      order.UserID,
+			ProgramCode: order.ProgramCode,
+			OrderID:     order.ID,
+			CampaignID:  &campaign.UUID,
+			Type:        models.TransactionTypeBonus,
+			Amount:      bonus,
+			CreatedAt:   now,
+		}
+
+		err = s.postTransaction(ctx, tx, transaction)
+		if err != nil {
+			return err
+		}
+
+		s.log(ctx).Info().
+			Str("orderID", order.ID).
+			Str("campaignID", campaign.UUID).
+			Int64("bonus", bonus).
+			Msg("campaign bonus credited")
+	}
+
+	return nil
+}
+
+// rewardReferral credits both sides of a pending referral once the referee's
+// first order is processed, unless the referrer has hit the monthly limit.
+func (s *Service) rewardReferral(ctx context.Context, tx pgx.Tx, order *models.OrderModel) error {
+	getReferralOpts := repository.GetReferralOptions{
+		RefereeID:     order.UserID,
+		LockForUpdate: true,
+	}
+	referral, err := s.repos.ReferralRepo.GetReferral(ctx, tx, getReferralOpts)
+	if err != nil {
+		if errors.Is(err, errs.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("can't get referral: %w", err)
+	}
+	if referral.Status != models.ReferralStatusPending {
+		return nil
+	}
+
+	// Serializes rewards of the same referrer so the limit can't be exceeded concurrently.
+	getReferrerOpts := repository.GetUserOptions{
+		UUID:          referral.ReferrerID,
+		LockForUpdate: true,
+	}
+	_, err = s.repos.UserRepo.GetUser(ctx, tx, getReferrerOpts)
+	if err != nil {
+		return fmt.Errorf("can't get referrer: %w", err)
+	}
+
+	now := time.Now()
+	rewarded, err := s.repos.ReferralRepo.CountRewardedReferrals(ctx, tx, referral.ReferrerID, now.Add(-models.ReferralLimitWindow))
+	if err != nil {
+		return fmt.Errorf("can't count rewarded referrals: %w", err)
+	}
+
+	if rewarded >= s.cfg.ReferralMonthlyLimit || s.cfg.ReferralReward <= 0 {
+		referral.Status = models.ReferralStatusRejected
+		s.log(ctx).Warn().
+			Str("referrerID", referral.ReferrerID).
+			Str("refereeID", referral.RefereeID).
+			Msg("referral reward rejected")
+		return s.repos.ReferralRepo.UpdateReferral(ctx, tx, referral)
+	}
+
+	for _, userID := range []string{referral.ReferrerID, referral.RefereeID} {
+		err = s.repos.AccountRepo.EnsureAccount(ctx, tx, models.NewAccount(userID, models.DefaultProgramCode))
+		if err != nil {
+			return fmt.Errorf("can't create account: %w", err)
+		}
+
+		transaction := &models.TransactionModel{
+			UUID:        uuid.NewString(),
+			UserID:      userID,
+			ProgramCode: models.DefaultProgramCode,
+			OrderID:     order.ID,
+			Type:        models.TransactionTypeReferral,
+			Amount:      s.cfg.ReferralReward,
+			CreatedAt:   now,
+		}
+
+		err = s.postTransaction(ctx, tx, transaction)
+		if err != nil {
+			return err
+		}
+	}
+
+	referral.Status = models.ReferralStatusRewarded
+	referral.RewardedAt = &now
+	return s.repos.ReferralRepo.UpdateReferral(ctx, tx, referral)
+}
+
+func (s *Service) GetUserReferrals(ctx context.Context, userID string) (models.ReferralModelList, error) {
+	var referrals models.ReferralModelList
+	err := db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
+		tx := db.GetTxFromContext(txCtx)
+		var err error
+		referrals, err = s.repos.ReferralRepo.GetReferralsForReferrer(txCtx, tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return referrals, nil
+}
+
+func (s *Service) getUserTier(ctx context.Context, tx pgx.Tx, userID string) (models.Tier, error) {
+	userTier, err := s.repos.TierRepo.GetUserTier(ctx, tx, userID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNoRows) {
+			return models.Tiers[0], nil
+		}
+		return models.Tier{}, fmt.Errorf("can't get user tier: %w", err)
+	}
+	return models.GetTier(userTier.Level), nil
+}
+
+// GetUserTier returns the tier snapshot written by the last recalculation.
+// Its accrued total is the one the level was computed from, and the level is
+// the one accruals are multiplied by, so the progress shown always matches
+// the tier applied. Users without a snapshot are on the lowest tier.
+func (s *Service) GetUserTier(ctx context.Context, userID string) (*models.UserTierModel, error) {
+	var userTier *models.UserTierModel
+	err := db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
+		tx := db.GetTxFromContext(txCtx)
+
+		var err error
+		userTier, err = s.repos.TierRepo.GetUserTier(txCtx, tx, userID)
+		if errors.Is(err, errs.ErrNoRows) {
+			userTier = &models.UserTierModel{
+				UserID: userID,
+				Level:  models.Tiers[0].Level,
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("can't get user tier: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return userTier, nil
+}
+
+func (s *Service) RecalculateTiers(ctx context.Context) (int64, error) {
+	var updated int64
+	err := db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
+		tx := db.GetTxFromContext(txCtx)
+		now := time.Now()
+
+		var err error
+		updated, err = s.repos.TierRepo.RecalculateTiers(txCtx, tx, now.Add(-models.TierWindow), now)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return updated, nil
+}
+
+func (s *Service) GetDeadLetterOrders(ctx context.Context) (models.OrderModelList, error) {
+	var orders models.OrderModelList
+	err := db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
+		tx := db.GetTxFromContext(txCtx)
+		var err error
+		orders, err = s.repos.OrderRepo.GetDeadLetterOrders(txCtx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (s *Service) RetryOrder(ctx context.Context, orderID string) (*models.OrderModel, error) {
+	var order *models.OrderModel
+	err := db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
+		tx := db.GetTxFromContext(txCtx)
+
+		getOrderOpts := repository.GetOrderOptions{
+			ID:            orderID,
+			LockForUpdate: true,
+		}
+		var err error
+		order, err = s.repos.OrderRepo.GetOrder(txCtx, tx, getOrderOpts)
+		if err != nil {
+			return err
+		}
+		if order.IsTerminated() {
+			return errs.ErrOrderTerminated
+		}
+
+		order.ResetSync(time.Now())
+		return s.repos.OrderRepo.UpdateOrder(txCtx, tx, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.wakeOrdersSync(ctx)
+	return order, nil
+}
+
+func (s *Service) CreateCampaign(ctx context.Context, campaign *models.CampaignModel) error {
+	return db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
+		tx := db.GetTxFromContext(txCtx)
+
+		if err := s.checkProgramExists(txCtx, tx, campaign.ProgramCode); err != nil {
+			return err
+		}
+
+		campaign.UUID = uuid.NewString()
+		return s.repos.CampaignRepo.CreateCampaign(txCtx, tx, campaign)
+	})
+}
+
+func (s *Service) GetCampaign(ctx context.Context, campaignID string) (*models.CampaignModel, error) {
+	var campaign *models.CampaignModel
+	err := db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
+		tx := db.GetTxFromContext(txCtx)
+		var err error
+		campaign, err = s.repos.CampaignRepo.GetCampaign(txCtx, tx, campaignID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return campaign, nil
+}
+
+func (s *Service) GetCampaigns(ctx context.Context) (models.CampaignModelList, error) {
+	var campaigns models.CampaignModelList
+	err := db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
+		tx := db.GetTxFromContext(txCtx)
+		var err error
+		campaigns, err = s.repos.CampaignRepo.GetCampaigns(txCtx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return campaigns, nil
+}
+
+func (s *Service) UpdateCampaign(ctx context.Context, campaign *models.CampaignModel) error {
+	return db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
+		tx := db.GetTxFromContext(txCtx)
+
+		if err := s.checkProgramExists(txCtx, tx, campaign.ProgramCode); err != nil {
+			return err
+		}
+
+		existing, err := s.repos.CampaignRepo.GetCampaign(txCtx, tx, campaign.UUID)
+		if err != nil {
+			return err
+		}
+
+		campaign.CreatedAt = existing.CreatedAt
+		return s.repos.CampaignRepo.UpdateCampaign(txCtx, tx, campaign)
+	})
+}
+
+func (s *Service) DeleteCampaign(ctx context.Context, campaignID string) error {
+	return db.WithTx(ctx, s.dbPool, func(txCtx context.Context) error {
+		tx := db.GetTxFromContext(txCtx)
+		return s.repos.CampaignRepo.DeleteCampaign(txCtx, tx, campaignID)
+	})
 }
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-gophermart/internal/accrualclient"
+	"github.com/etoneja/go-gophermart/internal/config"
+	"github.com/etoneja/go-gophermart/internal/db"
+	"github.com/etoneja/go-gophermart/internal/models"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestService runs the service against the database in TEST_DATABASE_URL,
+// the service SQL tests are skipped without one. All user data is removed
+// before each test.
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := db.NewDB(ctx, dsn)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	require.NoError(t, db.NewMigrator(pool, zerolog.Nop()).Migrate(ctx))
+	_, err = pool.Exec(ctx, `
+		TRUNCATE users, orders, transactions, accounts, user_tiers, campaigns,
+			referrals, transfers, rate_limits, jobs CASCADE
+	`)
+	require.NoError(t, err)
+
+	cfg := &config.Config{
+		JWTSecret:           "test-secret",
+		SyncBatchSize:       10,
+		SyncLease:           time.Minute,
+		SyncStaleAfter:      time.Hour,
+		SyncMaxAttempts:     3,
+		SyncBackoffBase:     time.Second,
+		SyncBackoffMax:      time.Minute,
+		SyncRecheckDelay:    10 * time.Second,
+		UnregisteredRecheck: 30 * time.Second,
+		UnregisteredTTL:     time.Hour,
+		SyncLaneWeights: map[models.SyncLane]int{
+			models.SyncLaneFresh:   1,
+			models.SyncLaneRecheck: 1,
+			models.SyncLaneStale:   1,
+		},
+	}
+	return NewService(cfg, pool, zerolog.Nop())
+}
+
+// newTestOrder registers a user with one uploaded order of the default
+// program.
+func newTestOrder(t *testing.T, s *Service, login, orderID string) *models.OrderModel {
+	t.Helper()
+
+	ctx := context.Background()
+	user, _, err := s.RegisterUser(ctx, login, "password", "")
+	require.NoError(t, err)
+
+	now := time.Now()
+	order, err := s.CreateOrGetOrder(ctx, &models.OrderModel{
+		ID:          orderID,
+		UserID:      user.UUID,
+		ProgramCode: models.DefaultProgramCode,
+		Status:      models.OrderStatusNew,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	require.NoError(t, err)
+	return order
+}
+
+func TestService_ApplyAccrualUpdate_ProcessingThenProcessed(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	order := newTestOrder(t, s, "processing", "12345678903")
+
+	accrual := int64(50000)
+	steps := []struct {
+		update     models.AccrualOrderModel
+		wantStatus models.OrderStatus
+	}{
+		{update: models.AccrualOrderModel{Status: models.AccrualOrderStatusRegistered}, wantStatus: models.OrderStatusNew},
+		{update: models.AccrualOrderModel{Status: models.AccrualOrderStatusProcessing}, wantStatus: models.OrderStatusProcessing},
+		{update: models.AccrualOrderModel{Status: models.AccrualOrderStatusProcessed, Accrual: &accrual}, wantStatus: models.OrderStatusProcessed},
+	}
+	for _, step := range steps {
+		step.update.ID = order.ID
+		require.NoError(t, s.ApplyAccrualUpdate(ctx, &step.update))
+
+		got, err := s.GetOrder(ctx, order.ID)
+		require.NoError(t, err)
+		assert.Equal(t, step.wantStatus, got.Status, "after %s", step.update.Status)
+	}
+
+	balance, err := s.GetUserBalance(ctx, order.UserID, models.DefaultProgramCode)
+	require.NoError(t, err)
+	assert.Equal(t, accrual, balance.Current)
+}
+
+func TestService_SyncOrders_ProcessingThenProcessed(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	order := newTestOrder(t, s, "syncing", "12345678903")
+
+	sync := func(status models.AccrualOrderStatus, accrual *int64) *models.OrderModel {
+		t.Helper()
+
+		s.accrualClient = accrualclient.NewStaticProvider(accrualclient.StaticRule{Status: status, Accrual: accrual})
+		claimed, err := s.ClaimOrdersToSync(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+
+		_, err = s.SyncOrders(ctx, []string{order.ID})
+		require.NoError(t, err)
+
+		got, err := s.GetOrder(ctx, order.ID)
+		require.NoError(t, err)
+		return got
+	}
+
+	got := sync(models.AccrualOrderStatusProcessing, nil)
+	assert.Equal(t, models.OrderStatusProcessing, got.Status)
+	if assert.NotNil(t, got.NextSyncAt, "an in-progress order is rechecked later") {
+		assert.True(t, got.NextSyncAt.After(time.Now()))
+	}
+
+	claimed, err := s.ClaimOrdersToSync(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed, "an in-progress order isn't due again before its recheck")
+
+	_, err = s.dbPool.Exec(ctx, `UPDATE orders SET next_sync_at = NOW() WHERE id = $1`, order.ID)
+	require.NoError(t, err)
+
+	accrual := int64(50000)
+	got = sync(models.AccrualOrderStatusProcessed, &accrual)
+	assert.Equal(t, models.OrderStatusProcessed, got.Status)
+	assert.Nil(t, got.NextSyncAt)
+
+	balance, err := s.GetUserBalance(ctx, order.UserID, models.DefaultProgramCode)
+	require.NoError(t, err)
+	assert.Equal(t, accrual, balance.Current)
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/etoneja/go-gophermart/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_GetUserTier_MatchesRecalculation(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	order := newTestOrder(t, s, "tiered", "12345678903")
+
+	accrual := models.GetTier(models.TierLevelSilver).Threshold
+	require.NoError(t, s.ApplyAccrualUpdate(ctx, &models.AccrualOrderModel{
+		ID:      order.ID,
+		Status:  models.AccrualOrderStatusProcessed,
+		Accrual: &accrual,
+	}))
+
+	tier, err := s.GetUserTier(ctx, order.UserID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TierLevelBronze, tier.Level, "the tier only changes on recalculation")
+	assert.Zero(t, tier.Accrued, "the accrued total is the one the tier was computed from")
+
+	_, err = s.RecalculateTiers(ctx)
+	require.NoError(t, err)
+
+	tier, err = s.GetUserTier(ctx, order.UserID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TierLevelSilver, tier.Level)
+	assert.Equal(t, accrual, tier.Accrued)
+}
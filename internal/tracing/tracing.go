@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const ServiceName = "gophermart"
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+type Options struct {
+	// Exporter is one of ExporterNone, ExporterStdout or ExporterOTLP.
+	Exporter string
+	// Endpoint is the OTLP/HTTP collector URL, the OTEL_EXPORTER_OTLP_*
+	// environment variables apply when it is empty.
+	Endpoint string
+	// SampleRatio is the share of new traces recorded, parent decisions
+	// from incoming trace context are kept.
+	SampleRatio float64
+	// Writer receives the spans of ExporterStdout, os.Stderr when nil so
+	// they don't mix into the JSON logs on stdout.
+	Writer io.Writer
+}
+
+// Setup installs the global tracer provider and the W3C trace-context
+// propagator. The returned func flushes pending spans and must be called on
+// shutdown. With ExporterNone spans aren't recorded but trace context is
+// still propagated.
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch opts.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		writer := opts.Writer
+		if writer == nil {
+			writer = os.Stderr
+		}
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(writer))
+	case ExporterOTLP:
+		var otlpOpts []otlptracehttp.Option
+		if opts.Endpoint != "" {
+			otlpOpts = append(otlpOpts, otlptracehttp.WithEndpointURL(opts.Endpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, otlpOpts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", opts.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't create %s trace exporter: %w", opts.Exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("can't build trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Tracer returns a tracer of the global provider named after the
+// instrumented package.
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer("github.com/etoneja/go-gophermart/internal/" + name)
+}
+
+// End records err on the span, unless it is a context cancellation, and ends
+// the span.
+func End(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, context.Canceled) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
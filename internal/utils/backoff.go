@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff returns an exponential delay for the given attempt (starting at 1),
+// capped at maxDelay, with jitter spreading it over [delay/2, delay]. A
+// non-positive maxDelay means no delay.
+func Backoff(attempt int, baseDelay, maxDelay time.Duration) time.Duration {
+	if maxDelay <= 0 {
+		return 0
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := maxDelay
+	if shift := attempt - 1; shift < 63 {
+		if d := baseDelay << shift; d > 0 && d < maxDelay {
+			delay = d
+		}
+	}
+
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		attempt int
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{name: "first attempt", attempt: 1, wantMin: 500 * time.Millisecond, wantMax: time.Second},
+		{name: "third attempt", attempt: 3, wantMin: 2 * time.Second, wantMax: 4 * time.Second},
+		{name: "capped", attempt: 20, wantMin: 30 * time.Second, wantMax: time.Minute},
+		{name: "huge attempt", attempt: 1000, wantMin: 30 * time.Second, wantMax: time.Minute},
+		{name: "zero attempt", attempt: 0, wantMin: 500 * time.Millisecond, wantMax: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 100 {
+				got := Backoff(tt.attempt, time.Second, time.Minute)
+				if got < tt.wantMin || got > tt.wantMax {
+					t.Fatalf("Backoff() = %v, want in [%v, %v]", got, tt.wantMin, tt.wantMax)
+				}
+			}
+		})
+	}
+}
+
+func TestBackoff_NonPositiveMax(t *testing.T) {
+	if got := Backoff(1, time.Second, -time.Second); got != 0 {
+		t.Fatalf("Backoff() = %v, want 0", got)
+	}
+	if got := Backoff(3, 0, 0); got != 0 {
+		t.Fatalf("Backoff() = %v, want 0", got)
+	}
+}
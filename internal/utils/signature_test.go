@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"order":"12345678903","status":"PROCESSED","accrual":500}`)
+	signature := SignPayload("secret", "1700000000", body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{name: "valid", secret: "secret", timestamp: "1700000000", body: body, signature: signature, want: true},
+		{name: "wrong secret", secret: "other", timestamp: "1700000000", body: body, signature: signature},
+		{name: "wrong timestamp", secret: "secret", timestamp: "1700000001", body: body, signature: signature},
+		{name: "tampered body", secret: "secret", timestamp: "1700000000", body: []byte(`{}`), signature: signature},
+		{name: "not hex", secret: "secret", timestamp: "1700000000", body: body, signature: "zz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, VerifySignature(tt.secret, tt.timestamp, tt.body, tt.signature))
+		})
+	}
+}
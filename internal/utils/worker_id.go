@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/google/uuid"
+)
+
+// NewWorkerID identifies this process in database leases, unique even for
+// several processes on one host.
+func NewWorkerID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString()[:8])
+}